@@ -2,13 +2,18 @@ package httptesting
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/golib/assert"
 )
 
 // AssertStatus asserts that the response status code is equal to value.
 func (r *Request) AssertStatus(status int) bool {
-	return assert.EqualValues(r.t, status, r.Response.StatusCode,
+	if !r.assertResponse() {
+		return false
+	}
+
+	return assert.EqualValues(r.reporter(), status, r.Response.StatusCode,
 		"Expected response status code of %d, but got %d",
 		status,
 		r.Response.StatusCode,
@@ -37,9 +42,13 @@ func (r *Request) AssertInternalError() bool {
 
 // AssertHeader asserts that the response includes named header with value.
 func (r *Request) AssertHeader(name, value string) bool {
+	if !r.assertResponse() {
+		return false
+	}
+
 	actual := r.Response.Header.Get(name)
 
-	return assert.EqualValues(r.t, value, actual,
+	return assert.EqualValues(r.reporter(), value, actual,
 		"Expected response header contains %s of %s, but got %s",
 		http.CanonicalHeaderKey(name),
 		value,
@@ -54,11 +63,15 @@ func (r *Request) AssertContentType(contentType string) bool {
 
 // AssertExistHeader asserts that the response includes named header.
 func (r *Request) AssertExistHeader(name string) bool {
+	if !r.assertResponse() {
+		return false
+	}
+
 	name = http.CanonicalHeaderKey(name)
 
 	_, ok := r.Response.Header[name]
 	if !ok {
-		assert.Fail(r.t, "Response header: "+name+" (*required)",
+		assert.Fail(r.reporter(), "Response header: "+name+" (*required)",
 			"Expected response header includes %s",
 			name,
 		)
@@ -69,11 +82,15 @@ func (r *Request) AssertExistHeader(name string) bool {
 
 // AssertNotExistHeader asserts that the response does not include named header.
 func (r *Request) AssertNotExistHeader(name string) bool {
+	if !r.assertResponse() {
+		return false
+	}
+
 	name = http.CanonicalHeaderKey(name)
 
 	_, ok := r.Response.Header[name]
 	if ok {
-		assert.Fail(r.t, "Response header: "+name+" (*not required)",
+		assert.Fail(r.reporter(), "Response header: "+name+" (*not required)",
 			"Expected response header does not include %s",
 			name,
 		)
@@ -82,19 +99,86 @@ func (r *Request) AssertNotExistHeader(name string) bool {
 	return !ok
 }
 
+// AssertPeerCertificate asserts that the server created by NewServer or NewServerWithTLS
+// saw a client certificate with common name given.
+func (r *Request) AssertPeerCertificate(commonName string) bool {
+	certs := r.PeerCertificates()
+	if len(certs) == 0 {
+		return assert.Fail(r.reporter(), "Peer certificate: "+commonName+" (*required)",
+			"Expected client presents certificate of %s, but got none",
+			commonName,
+		)
+	}
+
+	return assert.EqualValues(r.reporter(), commonName, certs[0].Subject.CommonName,
+		"Expected client presents certificate of %s, but got %s",
+		commonName,
+		certs[0].Subject.CommonName,
+	)
+}
+
+// AssertReceived asserts that the server created by NewServer or NewServerWithTLS with WithRecordRequests option
+// received a request of the method and path, which is relative to base path of the client. Query is compared only if the path contains it.
+func (r *Request) AssertReceived(method, path string) bool {
+	if !r.assertRecording() {
+		return false
+	}
+
+	target := r.basePath + path
+
+	received := r.ReceivedRequests()
+
+	actuals := make([]string, 0, len(received))
+	for _, request := range received {
+		uri := request.URL.Path
+		if strings.Contains(path, "?") {
+			uri = request.URL.RequestURI()
+		}
+
+		if strings.EqualFold(request.Method, method) && uri == target {
+			return true
+		}
+
+		actuals = append(actuals, request.Method+" "+request.URL.RequestURI())
+	}
+
+	return assert.Fail(r.reporter(), "Received request: "+method+" "+target+" (*required)",
+		"Expected server received request of %s %s, but got %v",
+		method,
+		target,
+		actuals,
+	)
+}
+
+// AssertReceivedCount asserts that the server created by NewServer or NewServerWithTLS with WithRecordRequests option
+// received n requests.
+func (r *Request) AssertReceivedCount(n int) bool {
+	if !r.assertRecording() {
+		return false
+	}
+
+	received := r.ReceivedRequests()
+
+	return assert.EqualValues(r.reporter(), n, len(received),
+		"Expected server received %d request(s), but got %d",
+		n,
+		len(received),
+	)
+}
+
 // AssertEmpty asserts that the response body is empty.
 func (r *Request) AssertEmpty() bool {
-	return assert.Empty(r.t, string(r.ResponseBody))
+	return assert.Empty(r.reporter(), string(r.ResponseBody))
 }
 
 // AssertNotEmpty asserts that the response body is not empty.
 func (r *Request) AssertNotEmpty() bool {
-	return assert.NotEmpty(r.t, string(r.ResponseBody))
+	return assert.NotEmpty(r.reporter(), string(r.ResponseBody))
 }
 
 // AssertContains asserts that the response body contains the string.
 func (r *Request) AssertContains(s string) bool {
-	return assert.Contains(r.t, string(r.ResponseBody), s,
+	return assert.Contains(r.reporter(), string(r.ResponseBody), s,
 		"Expected response body contains %q",
 		s,
 	)
@@ -102,7 +186,7 @@ func (r *Request) AssertContains(s string) bool {
 
 // AssertNotContains asserts that the response body does not contain the string.
 func (r *Request) AssertNotContains(s string) bool {
-	return assert.NotContains(r.t, string(r.ResponseBody), s,
+	return assert.NotContains(r.reporter(), string(r.ResponseBody), s,
 		"Expected response body does not contain %q",
 		s,
 	)
@@ -110,7 +194,7 @@ func (r *Request) AssertNotContains(s string) bool {
 
 // AssertMatch asserts that the response body matches the regular expression.
 func (r *Request) AssertMatch(re string) bool {
-	return assert.Match(r.t, re, r.ResponseBody,
+	return assert.Match(r.reporter(), re, r.ResponseBody,
 		"Expected response body matches regexp %q",
 		re,
 	)
@@ -118,7 +202,7 @@ func (r *Request) AssertMatch(re string) bool {
 
 // AssertNotMatch asserts that the response body does not match the regular expression.
 func (r *Request) AssertNotMatch(re string) bool {
-	return assert.NotMatch(r.t, re, r.ResponseBody,
+	return assert.NotMatch(r.reporter(), re, r.ResponseBody,
 		"Expected response body does not match regexp %q",
 		re,
 	)
@@ -126,10 +210,33 @@ func (r *Request) AssertNotMatch(re string) bool {
 
 // AssertContainsJSON asserts that the response body contains JSON value of the key.
 func (r *Request) AssertContainsJSON(key string, value interface{}) bool {
-	return assert.ContainsJSON(r.t, string(r.ResponseBody), key, value)
+	return assert.ContainsJSON(r.reporter(), string(r.ResponseBody), key, value)
 }
 
 // AssertNotContainsJSON asserts that the response body dose not contain JSON value of the key.
 func (r *Request) AssertNotContainsJSON(key string) bool {
-	return assert.NotContainsJSON(r.t, string(r.ResponseBody), key)
+	return assert.NotContainsJSON(r.reporter(), string(r.ResponseBody), key)
+}
+
+// assertRecording asserts that the client records inbound requests with WithRecordRequests option.
+func (r *Request) assertRecording() bool {
+	if r.recordRequests {
+		return true
+	}
+
+	return assert.Fail(r.reporter(), "Received requests: (*required)",
+		"Expected server records inbound requests with WithRecordRequests option, but got none",
+	)
+}
+
+// assertResponse asserts that the request has a response, which is absent after failure in non-fatal mode.
+func (r *Request) assertResponse() bool {
+	if r.Response != nil {
+		return true
+	}
+
+	return assert.Fail(r.reporter(), "Response: (*required)",
+		"Expected response, but got error %v",
+		r.Err(),
+	)
 }
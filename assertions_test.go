@@ -29,7 +29,7 @@ func TestRequest_Assertions(t *testing.T) {
 	ts := httptest.NewServer(server)
 	defer ts.Close()
 
-	request := New(ts.URL, false).New(t)
+	request := New(ts.URL).New(t)
 	request.Get(uri, params)
 	request.AssertOK()
 	request.AssertHeader("x-request-method", method)
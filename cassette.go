@@ -0,0 +1,412 @@
+package httptesting
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"unicode/utf8"
+)
+
+const (
+	cassetteVersion = 1
+
+	// CassetteRedacted replaces values of redacted headers within cassette file.
+	CassetteRedacted = "[REDACTED]"
+)
+
+// CassetteMode defines how a cassette deals with requests.
+type CassetteMode int
+
+// cassette modes
+const (
+	// CassetteAuto replays interactions if cassette file exists, and records otherwise.
+	CassetteAuto CassetteMode = iota
+	// CassetteRecord always sends requests to upstream and records interactions, the cassette
+	// file is rewritten by Save.
+	CassetteRecord
+	// CassetteReplay serves requests with recorded interactions only, a request matching none
+	// of interactions fails without touching network.
+	CassetteReplay
+)
+
+// CassetteOption defines a callback for customizing *Cassette.
+type CassetteOption func(*Cassette)
+
+// CassetteMatcher reports whether a request with body matches the recorded request.
+type CassetteMatcher func(r *http.Request, body []byte, recorded *CassetteRequest) bool
+
+// CassetteFilter defines a callback for changing interaction before it is recorded,
+// e.g. redacting secrets of body.
+type CassetteFilter func(interaction *CassetteInteraction)
+
+// CassetteInteraction defines a pair of request and response recorded by cassette.
+type CassetteInteraction struct {
+	Request  CassetteRequest  `json:"request"`
+	Response CassetteResponse `json:"response"`
+}
+
+// CassetteRequest defines request recorded by cassette.
+type CassetteRequest struct {
+	Method       string      `json:"method"`
+	URL          string      `json:"url"`
+	Header       http.Header `json:"header,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"body_encoding,omitempty"`
+}
+
+// CassetteResponse defines response recorded by cassette.
+type CassetteResponse struct {
+	StatusCode   int         `json:"status_code"`
+	Header       http.Header `json:"header,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"body_encoding,omitempty"`
+}
+
+type cassetteFile struct {
+	Version      int                    `json:"version"`
+	Interactions []*CassetteInteraction `json:"interactions"`
+}
+
+// Cassette records interactions of requests into a JSON file and replays them back,
+// it is used by Client with WithCassette option.
+type Cassette struct {
+	mux          sync.Mutex
+	filename     string
+	mode         CassetteMode
+	matchers     []CassetteMatcher
+	redacts      []string
+	filters      []CassetteFilter
+	interactions []*CassetteInteraction
+	replayed     map[*CassetteInteraction]bool
+	loadOnce     sync.Once
+	loadErr      error
+	dirty        bool
+}
+
+// NewCassette returns a *Cassette backed by the file given. By default, requests are matched by
+// method and URL, and values of Authorization, Proxy-Authorization, Cookie and Set-Cookie headers
+// are redacted before recording.
+func NewCassette(filename string, mode CassetteMode, opts ...CassetteOption) *Cassette {
+	cassette := &Cassette{
+		filename: filename,
+		mode:     mode,
+		matchers: []CassetteMatcher{MatchMethod(), MatchURL()},
+		redacts:  []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"},
+		replayed: map[*CassetteInteraction]bool{},
+	}
+	for _, opt := range opts {
+		opt(cassette)
+	}
+
+	return cassette
+}
+
+// WithCassetteMatchers replaces matchers used to find recorded interaction for a request in replay.
+func WithCassetteMatchers(matchers ...CassetteMatcher) CassetteOption {
+	return func(cassette *Cassette) {
+		cassette.matchers = matchers
+	}
+}
+
+// WithCassetteRedacts adds names of headers whose values are replaced with CassetteRedacted
+// before recording.
+func WithCassetteRedacts(names ...string) CassetteOption {
+	return func(cassette *Cassette) {
+		cassette.redacts = append(cassette.redacts, names...)
+	}
+}
+
+// WithCassetteFilters adds filters applied to interaction before recording.
+func WithCassetteFilters(filters ...CassetteFilter) CassetteOption {
+	return func(cassette *Cassette) {
+		cassette.filters = append(cassette.filters, filters...)
+	}
+}
+
+// MatchMethod matches requests by method.
+func MatchMethod() CassetteMatcher {
+	return func(r *http.Request, body []byte, recorded *CassetteRequest) bool {
+		return r.Method == recorded.Method
+	}
+}
+
+// MatchURL matches requests by absolute URL, including query.
+func MatchURL() CassetteMatcher {
+	return func(r *http.Request, body []byte, recorded *CassetteRequest) bool {
+		return r.URL.String() == recorded.URL
+	}
+}
+
+// MatchBody matches requests by body.
+func MatchBody() CassetteMatcher {
+	return func(r *http.Request, body []byte, recorded *CassetteRequest) bool {
+		recordedBody, err := decodeBody(recorded.Body, recorded.BodyEncoding)
+
+		return err == nil && bytes.Equal(body, recordedBody)
+	}
+}
+
+// MatchHeaders matches requests by values of headers named. A redacted header matches any value.
+func MatchHeaders(names ...string) CassetteMatcher {
+	return func(r *http.Request, body []byte, recorded *CassetteRequest) bool {
+		for _, name := range names {
+			values := recorded.Header.Values(name)
+			if len(values) == 1 && values[0] == CassetteRedacted && len(r.Header.Values(name)) > 0 {
+				continue
+			}
+
+			if fmt.Sprint(values) != fmt.Sprint(r.Header.Values(name)) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// Interactions returns all interactions of the cassette.
+func (cassette *Cassette) Interactions() []*CassetteInteraction {
+	cassette.mux.Lock()
+	defer cassette.mux.Unlock()
+
+	return append([]*CassetteInteraction(nil), cassette.interactions...)
+}
+
+// Save writes recorded interactions into the cassette file. It does nothing unless
+// new interactions recorded.
+func (cassette *Cassette) Save() error {
+	cassette.mux.Lock()
+	defer cassette.mux.Unlock()
+
+	if !cassette.dirty {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(&cassetteFile{
+		Version:      cassetteVersion,
+		Interactions: cassette.interactions,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(cassette.filename), 0755)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(cassette.filename, append(data, '\n'), 0644)
+	if err != nil {
+		return err
+	}
+
+	cassette.dirty = false
+	return nil
+}
+
+// Transport returns a http.RoundTripper which records interactions of transport given, or replays
+// recorded interactions depending on mode of the cassette.
+func (cassette *Cassette) Transport(transport http.RoundTripper) http.RoundTripper {
+	return &cassetteTransport{
+		cassette:  cassette,
+		transport: transport,
+	}
+}
+
+func (cassette *Cassette) load() error {
+	cassette.loadOnce.Do(func() {
+		if cassette.mode == CassetteRecord {
+			return
+		}
+
+		data, err := os.ReadFile(cassette.filename)
+		if err != nil {
+			if cassette.mode == CassetteAuto && errors.Is(err, os.ErrNotExist) {
+				cassette.mode = CassetteRecord
+				return
+			}
+
+			cassette.loadErr = err
+			return
+		}
+
+		var file cassetteFile
+
+		err = json.Unmarshal(data, &file)
+		if err != nil {
+			cassette.loadErr = fmt.Errorf("cassette %s: %v", cassette.filename, err)
+			return
+		}
+
+		if cassette.mode == CassetteAuto {
+			cassette.mode = CassetteReplay
+		}
+		cassette.interactions = file.Interactions
+	})
+
+	return cassette.loadErr
+}
+
+func (cassette *Cassette) replay(r *http.Request, body []byte) (*http.Response, error) {
+	cassette.mux.Lock()
+	defer cassette.mux.Unlock()
+
+	// prefer interaction which has not been replayed for requests sent repeatedly
+	var matched *CassetteInteraction
+	for _, interaction := range cassette.interactions {
+		if !cassette.match(r, body, &interaction.Request) {
+			continue
+		}
+
+		matched = interaction
+		if !cassette.replayed[interaction] {
+			break
+		}
+	}
+	if matched == nil {
+		return nil, fmt.Errorf("cassette %s: no interaction matches %s %s", cassette.filename, r.Method, r.URL)
+	}
+
+	cassette.replayed[matched] = true
+
+	responseBody, err := decodeBody(matched.Response.Body, matched.Response.BodyEncoding)
+	if err != nil {
+		return nil, fmt.Errorf("cassette %s: %v", cassette.filename, err)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", matched.Response.StatusCode, http.StatusText(matched.Response.StatusCode)),
+		StatusCode:    matched.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        matched.Response.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(responseBody)),
+		ContentLength: int64(len(responseBody)),
+		Request:       r,
+	}, nil
+}
+
+func (cassette *Cassette) match(r *http.Request, body []byte, recorded *CassetteRequest) bool {
+	for _, matcher := range cassette.matchers {
+		if !matcher(r, body, recorded) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (cassette *Cassette) record(r *http.Request, body []byte, resp *http.Response, responseBody []byte) {
+	interaction := &CassetteInteraction{
+		Request: CassetteRequest{
+			Method: r.Method,
+			URL:    r.URL.String(),
+			Header: r.Header.Clone(),
+		},
+		Response: CassetteResponse{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header.Clone(),
+		},
+	}
+	interaction.Request.Body, interaction.Request.BodyEncoding = encodeBody(body)
+	interaction.Response.Body, interaction.Response.BodyEncoding = encodeBody(responseBody)
+
+	for _, name := range cassette.redacts {
+		if len(interaction.Request.Header.Values(name)) > 0 {
+			interaction.Request.Header.Set(name, CassetteRedacted)
+		}
+		if len(interaction.Response.Header.Values(name)) > 0 {
+			interaction.Response.Header.Set(name, CassetteRedacted)
+		}
+	}
+
+	for _, filter := range cassette.filters {
+		filter(interaction)
+	}
+
+	cassette.mux.Lock()
+	defer cassette.mux.Unlock()
+
+	cassette.interactions = append(cassette.interactions, interaction)
+	cassette.dirty = true
+}
+
+type cassetteTransport struct {
+	cassette  *Cassette
+	transport http.RoundTripper
+}
+
+func (transport *cassetteTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	cassette := transport.cassette
+
+	err := cassette.load()
+	if err != nil {
+		return nil, err
+	}
+
+	var body []byte
+	if r.Body != nil {
+		body, err = io.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	if cassette.mode == CassetteReplay {
+		return cassette.replay(r, body)
+	}
+
+	resp, err := transport.transport.RoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+
+	responseBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	cassette.record(r, body, resp, responseBody)
+
+	return resp, nil
+}
+
+func (transport *cassetteTransport) CloseIdleConnections() {
+	if closer, ok := transport.transport.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+func encodeBody(body []byte) (string, string) {
+	if utf8.Valid(body) {
+		return string(body), ""
+	}
+
+	return base64.StdEncoding.EncodeToString(body), "base64"
+}
+
+func decodeBody(body, encoding string) ([]byte, error) {
+	switch encoding {
+	case "":
+		return []byte(body), nil
+
+	case "base64":
+		return base64.StdEncoding.DecodeString(body)
+	}
+
+	return nil, fmt.Errorf("unsupported body encoding %q", encoding)
+}
@@ -0,0 +1,128 @@
+package httptesting
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+func TestClient_WithCassette(t *testing.T) {
+	it := assert.New(t)
+
+	var hits int
+	server := newMockServer("POST", "/cassette", func(w http.ResponseWriter, r *http.Request) {
+		hits++
+
+		body, _ := io.ReadAll(r.Body)
+
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.WriteHeader(http.StatusCreated)
+		w.Write(append([]byte("echo "), body...))
+	})
+
+	ts := httptest.NewServer(server)
+
+	filename := filepath.Join(t.TempDir(), "cassettes", "echo.json")
+
+	// it should record interactions
+	client := New(ts.URL, WithCassette(NewCassette(filename, CassetteAuto, WithCassetteFilters(func(interaction *CassetteInteraction) {
+		interaction.Request.Body = strings.ReplaceAll(interaction.Request.Body, "s3cr3t", "[TOKEN]")
+		interaction.Response.Body = strings.ReplaceAll(interaction.Response.Body, "s3cr3t", "[TOKEN]")
+	}))))
+
+	request := client.New(t)
+	request.WithHeader("Authorization", "Bearer s3cr3t")
+	request.Post("/cassette", "text/plain", []byte("hello, s3cr3t"))
+	request.AssertStatus(http.StatusCreated)
+	request.AssertContains("echo hello, s3cr3t")
+
+	request.Post("/cassette", "text/plain", []byte("world"))
+	request.AssertContains("echo world")
+
+	it.Nil(client.Close())
+	ts.Close()
+	it.Equal(2, hits)
+
+	data, err := os.ReadFile(filename)
+	if it.Nil(err) {
+		it.Contains(string(data), `"Authorization": [`+"\n"+`            "[REDACTED]"`)
+		it.Contains(string(data), `"body": "hello, [TOKEN]"`)
+		it.NotContains(string(data), "s3cr3t")
+		it.NotContains(string(data), "session=secret")
+	}
+
+	// it should replay interactions without network
+	client = New(ts.URL, WithCassette(NewCassette(filename, CassetteAuto, WithCassetteMatchers(MatchMethod(), MatchURL(), MatchBody()))))
+	defer client.Close()
+
+	request = client.New(t)
+	request.Post("/cassette", "text/plain", []byte("world"))
+	request.AssertStatus(http.StatusCreated)
+	request.AssertContains("echo world")
+	request.AssertHeader("Set-Cookie", CassetteRedacted)
+
+	// it should fail for request matching none of interactions
+	harness := &mockTesting{}
+
+	request = client.New(harness).NonFatal()
+	request.Post("/cassette", "text/plain", []byte("unknown"))
+	if it.NotNil(request.Err()) {
+		it.Contains(request.Err().Error(), "no interaction matches POST "+ts.URL+"/cassette")
+	}
+	it.Equal(2, hits)
+}
+
+func TestCassette_Replay(t *testing.T) {
+	it := assert.New(t)
+
+	binary := []byte{0xff, 0xfe, 0x00, 0x01}
+
+	cassette := NewCassette(filepath.Join(t.TempDir(), "replay.json"), CassetteRecord)
+	cassette.record(
+		httptest.NewRequest("GET", "http://example.com/users", nil),
+		nil,
+		&http.Response{StatusCode: http.StatusOK, Header: http.Header{}},
+		[]byte("first"),
+	)
+	cassette.record(
+		httptest.NewRequest("GET", "http://example.com/users", nil),
+		nil,
+		&http.Response{StatusCode: http.StatusOK, Header: http.Header{}},
+		binary,
+	)
+
+	interactions := cassette.Interactions()
+	if it.Len(interactions, 2) {
+		it.Equal("base64", interactions[1].Response.BodyEncoding)
+	}
+	it.Nil(cassette.Save())
+
+	// it should replay interactions in order, and reuse the last one
+	cassette = NewCassette(cassette.filename, CassetteReplay, WithCassetteMatchers(MatchMethod(), MatchURL(), MatchHeaders("X-Request-Id")))
+
+	transport := cassette.Transport(roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		t.Fatalf("unexpected request to network: %s", r.URL)
+		return nil, nil
+	}))
+
+	for _, expected := range [][]byte{[]byte("first"), binary, binary} {
+		resp, err := transport.RoundTrip(httptest.NewRequest("GET", "http://example.com/users", nil))
+		if it.Nil(err) {
+			body, _ := io.ReadAll(resp.Body)
+			it.True(bytes.Equal(expected, body))
+		}
+	}
+
+	request := httptest.NewRequest("GET", "http://example.com/users", nil)
+	request.Header.Set("X-Request-Id", "1")
+
+	_, err := transport.RoundTrip(request)
+	it.NotNil(err)
+}
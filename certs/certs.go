@@ -0,0 +1,165 @@
+// Package certs generates ephemeral certificate authority and leaf certificates for TLS testing.
+package certs
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+)
+
+// CA defines a certificate authority for issuing leaf certificates.
+type CA struct {
+	Certificate *x509.Certificate
+	PrivateKey  crypto.Signer
+}
+
+// NewCA returns a self-signed certificate authority.
+func NewCA(opts ...Option) (*CA, error) {
+	config := newConfig("httptesting CA", opts)
+
+	key, err := config.newKey()
+	if err != nil {
+		return nil, err
+	}
+
+	template, err := config.newTemplate()
+	if err != nil {
+		return nil, err
+	}
+	template.IsCA = true
+	template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature
+
+	cert, err := createCertificate(template, template, key.Public(), key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CA{
+		Certificate: cert,
+		PrivateKey:  key,
+	}, nil
+}
+
+// Pool returns a cert pool containing certificate of the CA.
+func (ca *CA) Pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Certificate)
+
+	return pool
+}
+
+// Issue returns a leaf certificate signed by the CA along with its chain.
+func (ca *CA) Issue(opts ...Option) (tls.Certificate, error) {
+	config := newConfig("httptesting", opts)
+
+	key, err := config.newKey()
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	template, err := config.newTemplate()
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	template.KeyUsage = x509.KeyUsageDigitalSignature
+	if config.keyType == RSA {
+		template.KeyUsage |= x509.KeyUsageKeyEncipherment
+	}
+
+	template.ExtKeyUsage = config.usages
+	if len(template.ExtKeyUsage) == 0 {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	}
+
+	hosts := config.hosts
+	if len(hosts) == 0 {
+		hosts = []string{"127.0.0.1", "::1", "localhost"}
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	cert, err := createCertificate(template, ca.Certificate, key.Public(), ca.PrivateKey)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return tls.Certificate{
+		Certificate: [][]byte{cert.Raw, ca.Certificate.Raw},
+		PrivateKey:  key,
+		Leaf:        cert,
+	}, nil
+}
+
+// EncodePEM returns PEM encoded certificate chain and private key of cert, which can be used by tls.X509KeyPair.
+func EncodePEM(cert tls.Certificate) (certPEM, keyPEM []byte, err error) {
+	for _, der := range cert.Certificate {
+		certPEM = append(certPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	return
+}
+
+func (c *config) newKey() (crypto.Signer, error) {
+	switch c.keyType {
+	case RSA:
+		return rsa.GenerateKey(rand.Reader, c.rsaBits)
+
+	case ECDSA:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	case Ed25519:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	}
+
+	return nil, fmt.Errorf("certs: unsupported key type %q", c.keyType)
+}
+
+func (c *config) newTemplate() (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	return &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			Organization: []string{"httptesting"},
+			CommonName:   c.commonName,
+		},
+		NotBefore:             c.notBefore,
+		NotAfter:              c.notAfter,
+		BasicConstraintsValid: true,
+	}, nil
+}
+
+func createCertificate(template, parent *x509.Certificate, pub crypto.PublicKey, priv crypto.Signer) (*x509.Certificate, error) {
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, pub, priv)
+	if err != nil {
+		return nil, err
+	}
+
+	return x509.ParseCertificate(der)
+}
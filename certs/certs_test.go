@@ -0,0 +1,132 @@
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golib/assert"
+)
+
+func TestNewCA(t *testing.T) {
+	it := assert.New(t)
+
+	ca, err := NewCA()
+	if it.Nil(err) {
+		it.True(ca.Certificate.IsCA)
+		it.Equal("httptesting CA", ca.Certificate.Subject.CommonName)
+		it.Equal(x509.ECDSA, ca.Certificate.PublicKeyAlgorithm)
+	}
+
+	_, err = NewCA(WithKeyType("dsa"))
+	it.NotNil(err)
+}
+
+func TestCA_Issue(t *testing.T) {
+	it := assert.New(t)
+
+	for _, keyType := range []KeyType{RSA, ECDSA, Ed25519} {
+		ca, err := NewCA(WithKeyType(keyType))
+		if !it.Nil(err) {
+			continue
+		}
+
+		cert, err := ca.Issue(WithKeyType(keyType), WithHosts("127.0.0.1", "www.example.com"))
+		if !it.Nil(err) {
+			continue
+		}
+		it.Equal([]string{"www.example.com"}, cert.Leaf.DNSNames)
+		it.Len(cert.Leaf.IPAddresses, 1)
+
+		_, err = cert.Leaf.Verify(x509.VerifyOptions{
+			DNSName: "www.example.com",
+			Roots:   ca.Pool(),
+		})
+		it.Nil(err, "Expected %s certificate verified by CA", keyType)
+
+		// it should work with TLS server
+		ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("OK"))
+		}))
+		ts.TLS = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+		}
+		ts.StartTLS()
+
+		client := &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					RootCAs: ca.Pool(),
+				},
+			},
+		}
+
+		resp, err := client.Get(ts.URL)
+		if it.Nil(err, "Expected %s certificate works with TLS", keyType) {
+			resp.Body.Close()
+		}
+
+		ts.Close()
+	}
+}
+
+func TestCA_IssueWithValidity(t *testing.T) {
+	it := assert.New(t)
+
+	ca, err := NewCA()
+	if !it.Nil(err) {
+		return
+	}
+
+	for _, opt := range []Option{Expired(), NotYetValid()} {
+		cert, err := ca.Issue(opt)
+		if !it.Nil(err) {
+			continue
+		}
+
+		_, err = cert.Leaf.Verify(x509.VerifyOptions{
+			DNSName: "localhost",
+			Roots:   ca.Pool(),
+		})
+		if it.NotNil(err) {
+			invalidErr, ok := err.(x509.CertificateInvalidError)
+			if it.True(ok) {
+				it.Equal(x509.Expired, invalidErr.Reason)
+			}
+		}
+	}
+
+	notBefore := time.Now().Add(-time.Minute).Truncate(time.Second)
+	notAfter := notBefore.Add(time.Hour)
+
+	cert, err := ca.Issue(WithValidity(notBefore, notAfter))
+	if it.Nil(err) {
+		it.True(cert.Leaf.NotBefore.Equal(notBefore))
+		it.True(cert.Leaf.NotAfter.Equal(notAfter))
+	}
+}
+
+func TestEncodePEM(t *testing.T) {
+	it := assert.New(t)
+
+	ca, err := NewCA()
+	if !it.Nil(err) {
+		return
+	}
+
+	cert, err := ca.Issue(WithRSABits(2048))
+	if !it.Nil(err) {
+		return
+	}
+
+	certPEM, keyPEM, err := EncodePEM(cert)
+	if it.Nil(err) {
+		pair, err := tls.X509KeyPair(certPEM, keyPEM)
+		if it.Nil(err) {
+			it.Len(pair.Certificate, 2)
+		}
+	}
+}
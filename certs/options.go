@@ -0,0 +1,104 @@
+package certs
+
+import (
+	"crypto/x509"
+	"time"
+)
+
+// KeyType defines algorithm of private key generated for certificates.
+type KeyType string
+
+// Supported key types
+const (
+	RSA     KeyType = "rsa"
+	ECDSA   KeyType = "ecdsa"
+	Ed25519 KeyType = "ed25519"
+)
+
+// Option defines a callback for customizing generated certificates.
+type Option func(*config)
+
+type config struct {
+	commonName string
+	hosts      []string
+	keyType    KeyType
+	rsaBits    int
+	notBefore  time.Time
+	notAfter   time.Time
+	usages     []x509.ExtKeyUsage
+}
+
+func newConfig(commonName string, opts []Option) *config {
+	now := time.Now()
+
+	c := &config{
+		commonName: commonName,
+		keyType:    ECDSA,
+		rsaBits:    2048,
+		notBefore:  now.Add(-time.Hour),
+		notAfter:   now.Add(24 * time.Hour),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// WithCommonName sets subject common name of certificate.
+func WithCommonName(name string) Option {
+	return func(c *config) {
+		c.commonName = name
+	}
+}
+
+// WithHosts sets DNS names and IP addresses of certificate, it defaults to 127.0.0.1, ::1 and localhost.
+func WithHosts(hosts ...string) Option {
+	return func(c *config) {
+		c.hosts = append(c.hosts, hosts...)
+	}
+}
+
+// WithKeyType sets algorithm of private key, it defaults to ECDSA with P-256 curve.
+func WithKeyType(keyType KeyType) Option {
+	return func(c *config) {
+		c.keyType = keyType
+	}
+}
+
+// WithRSABits sets size of RSA private key, it defaults to 2048.
+func WithRSABits(bits int) Option {
+	return func(c *config) {
+		c.keyType = RSA
+		c.rsaBits = bits
+	}
+}
+
+// WithValidity sets validity window of certificate, it defaults to one hour ago till 24 hours later.
+func WithValidity(notBefore, notAfter time.Time) Option {
+	return func(c *config) {
+		c.notBefore = notBefore
+		c.notAfter = notAfter
+	}
+}
+
+// Expired sets validity window of certificate ended 24 hours ago.
+func Expired() Option {
+	now := time.Now()
+
+	return WithValidity(now.Add(-48*time.Hour), now.Add(-24*time.Hour))
+}
+
+// NotYetValid sets validity window of certificate started 24 hours later.
+func NotYetValid() Option {
+	now := time.Now()
+
+	return WithValidity(now.Add(24*time.Hour), now.Add(48*time.Hour))
+}
+
+// WithExtKeyUsage sets extended key usages of leaf certificate, it defaults to both server and client auth.
+func WithExtKeyUsage(usages ...x509.ExtKeyUsage) Option {
+	return func(c *config) {
+		c.usages = append(c.usages, usages...)
+	}
+}
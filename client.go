@@ -1,7 +1,9 @@
 package httptesting
 
 import (
+	"crypto/tls"
 	"crypto/x509"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/cookiejar"
@@ -9,7 +11,7 @@ import (
 	"net/url"
 	"strings"
 	"sync"
-	"testing"
+	"time"
 
 	"golang.org/x/net/websocket"
 )
@@ -18,16 +20,46 @@ import (
 //
 // NOTE: Client is not safe for concurrency, please use client.New(t) after initialized.
 type Client struct {
-	mux    sync.RWMutex
-	server *httptest.Server
-	host   string
-	certs  *x509.CertPool
-	jar    *cookiejar.Jar
-	isTLS  bool
+	mux         sync.RWMutex
+	server      *httptest.Server
+	inMemory    bool
+	host        string
+	basePath    string
+	header      http.Header
+	certs       *x509.CertPool
+	clientCerts []tls.Certificate
+	serverName  string
+	insecureTLS bool
+	clientAuth  tls.ClientAuthType
+	clientCAs   *x509.CertPool
+	jar         http.CookieJar
+	timeout     time.Duration
+	isTLS       bool
+
+	transportOnce   sync.Once
+	transport       http.RoundTripper
+	transportConfig TransportConfig
+	cassette        *Cassette
+	faults          *FaultInjector
+	middlewares     []Middleware
+	har             *harRecorder
+	verbose         bool
+	color           bool
+	debugBodyLimit  int
+	mock            *MockServer
+
+	recordRequests bool
+	recordLimit    int
+	receivedMux    sync.Mutex
+	received       []*ReceivedRequest
+	peerCerts      []*x509.Certificate
+	connPeerCerts  map[string][]*x509.Certificate // keyed by remote address of connections
 }
 
 // New returns an initialized *Client ready for testing
-func New(host string, isTLS bool) *Client {
+func New(host string, opts ...Option) *Client {
+	var isTLS bool
+
 	// adjust host
 	if strings.HasPrefix(host, "http://") || strings.HasPrefix(host, "https://") {
 		urlobj, err := url.Parse(host)
@@ -38,42 +70,38 @@ func New(host string, isTLS bool) *Client {
 		}
 	}
 
-	jar, err := cookiejar.New(nil)
-	if err != nil {
-		panic(fmt.Sprintf("httptesting: New: %v", err))
-	}
+	client := newClient(isTLS, opts)
+	client.host = host
 
-	return &Client{
-		host:  host,
-		jar:   jar,
-		isTLS: isTLS,
-	}
+	return client
 }
 
 // NewWithTLS returns an initialized *Client with custom certificate.
-func NewWithTLS(host string, cert *x509.Certificate) *Client {
-	// adjust host
-	if strings.HasPrefix(host, "https://") {
-		urlobj, err := url.Parse(host)
-		if err == nil {
-			host = urlobj.Host
-		}
-	}
+func NewWithTLS(host string, cert *x509.Certificate, opts ...Option) *Client {
+	certs := x509.NewCertPool()
+	certs.AddCert(cert)
 
+	return New(host, append([]Option{WithTLS(), WithRootCAs(certs)}, opts...)...)
+}
+
+func newClient(isTLS bool, opts []Option) *Client {
 	jar, err := cookiejar.New(nil)
 	if err != nil {
-		panic(fmt.Sprintf("httptesting: NewWithTLS: %v", err))
+		panic(fmt.Sprintf("httptesting: New: %v", err))
 	}
 
-	certs := x509.NewCertPool()
-	certs.AddCert(cert)
-
-	return &Client{
-		host:  host,
-		certs: certs,
-		jar:   jar,
-		isTLS: true,
+	client := &Client{
+		header:          http.Header{},
+		jar:             jar,
+		isTLS:           isTLS,
+		transportConfig: DefaultTransportConfig,
+		debugBodyLimit:  DefaultDebugBodyLimit,
+	}
+	for _, opt := range opts {
+		opt(client)
 	}
+
+	return client
 }
 
 // Host returns the host and port of the server, e.g. "127.0.0.1:9090"
@@ -105,7 +133,7 @@ func (c *Client) Url(urlpath string, params ...url.Values) string {
 		scheme = "https://"
 	}
 
-	return scheme + c.Host() + urlpath
+	return scheme + c.Host() + c.basePath + urlpath
 }
 
 // WebsocketUrl returns the abs websocket URL of the resource, e.g. "ws://127.0.0.1:9090/status"
@@ -118,11 +146,15 @@ func (c *Client) WebsocketUrl(urlpath string, params ...url.Values) string {
 		urlpath += params[0].Encode()
 	}
 
-	return "ws://" + c.Host() + urlpath
+	return "ws://" + c.Host() + c.basePath + urlpath
 }
 
 // Cookies returns jar related to the host
 func (c *Client) Cookies() ([]*http.Cookie, error) {
+	if c.jar == nil {
+		return nil, nil
+	}
+
 	urlobj, err := url.Parse(c.Url("/"))
 	if err != nil {
 		return nil, err
@@ -136,6 +168,10 @@ func (c *Client) SetCookies(cookies []*http.Cookie) error {
 	c.mux.Lock()
 	defer c.mux.Unlock()
 
+	if c.jar == nil {
+		return nil
+	}
+
 	urlobj, err := url.Parse(c.Url("/"))
 	if err != nil {
 		return err
@@ -147,21 +183,80 @@ func (c *Client) SetCookies(cookies []*http.Cookie) error {
 
 // NewClient creates a http client with cookie and tls for the Client.
 func (c *Client) NewClient(filters ...RequestFilter) *http.Client {
+	transport := c.Transport()
+
 	client := &http.Client{
-		Transport: NewFilterTransport(filters, c.certs),
-		Jar:       c.jar,
+		Transport: &FilterTransport{
+			filters:   filters,
+			transport: transport,
+			chain:     transport,
+		},
+		Jar:     c.jar,
+		Timeout: c.timeout,
 	}
 
 	return client
 }
 
+// Transport returns the underlying http.RoundTripper shared by all requests of the Client.
+// It is created once with TransportConfig of the Client unless WithTransport option given,
+// and wrapped by cassette given by WithCassette option, then by injector given by WithFaults option,
+// then by recorder of WithHAR option, and finally by middlewares of WithMiddlewares option.
+func (c *Client) Transport() http.RoundTripper {
+	c.transportOnce.Do(func() {
+		if c.transport == nil {
+			c.transport = c.transportConfig.NewTransport(c.tlsConfig())
+		}
+
+		if c.cassette != nil {
+			c.transport = c.cassette.Transport(c.transport)
+		}
+
+		if c.faults != nil {
+			c.transport = c.faults.Transport(c.transport)
+		}
+
+		if c.har != nil {
+			c.transport = c.har.Transport(c.transport)
+		}
+
+		c.transport = chainMiddlewares(c.transport, c.middlewares)
+	})
+
+	return c.transport
+}
+
+func (c *Client) tlsConfig() *tls.Config {
+	if c.certs == nil && len(c.clientCerts) == 0 && len(c.serverName) == 0 && !c.insecureTLS {
+		return nil
+	}
+
+	return &tls.Config{
+		RootCAs:            c.certs,
+		Certificates:       c.clientCerts,
+		ServerName:         c.serverName,
+		InsecureSkipVerify: c.insecureTLS,
+	}
+}
+
+// PeerCertificates returns certificate chain presented by client of the latest request
+// served by server created by NewServer or NewServerWithTLS, which is overwritten by requests
+// issued concurrently. Use Request.PeerCertificates for chain of a specific request.
+func (c *Client) PeerCertificates() []*x509.Certificate {
+	c.receivedMux.Lock()
+	defer c.receivedMux.Unlock()
+
+	return c.peerCerts
+}
+
 // NewWebsocket creates a websocket connection to the given path and returns the connection
-func (c *Client) NewWebsocket(t *testing.T, path string) *websocket.Conn {
+func (c *Client) NewWebsocket(t TestingT, path string) *websocket.Conn {
 	origin := c.WebsocketUrl("/")
 	target := c.WebsocketUrl(path)
 
 	ws, err := websocket.Dial(target, "", origin)
 	if err != nil {
+		t.Helper()
 		t.Fatalf("httptesting: NewWebscoket: connect %s with %v\n", path, err)
 	}
 
@@ -169,24 +264,55 @@ func (c *Client) NewWebsocket(t *testing.T, path string) *websocket.Conn {
 }
 
 // NewRequest returns a *Request which has more customization!
-func (c *Client) NewRequest(t *testing.T) *Request {
+func (c *Client) NewRequest(t TestingT) *Request {
 	return NewRequest(t, c)
 }
 
 // New is alias of NewRequest for shortcut.
-func (c *Client) New(t *testing.T) *Request {
+func (c *Client) New(t TestingT) *Request {
 	return c.NewRequest(t)
 }
 
 // Close tries to
 //
+//   - close idle connections of the underlying transport
 //   - close *httptest.Server created by NewServer or NewServerWithTLS
-func (c *Client) Close() {
+//   - assert expectations of *MockServer created by NewMockServer
+//   - save cassette given by WithCassette option
+//   - save HAR file given by WithHAR option
+//
+// It returns errors of saving cassette and HAR file, while cleanups above are always done.
+func (c *Client) Close() error {
 	c.mux.Lock()
 	defer c.mux.Unlock()
 
+	var errs []error
+
+	if c.cassette != nil {
+		if err := c.cassette.Save(); err != nil {
+			errs = append(errs, fmt.Errorf("httptesting: Close: cassette: %w", err))
+		}
+	}
+
+	if c.har != nil {
+		if err := c.har.save(); err != nil {
+			errs = append(errs, fmt.Errorf("httptesting: Close: HAR: %w", err))
+		}
+	}
+
+	if transport, ok := c.transport.(interface{ CloseIdleConnections() }); ok {
+		transport.CloseIdleConnections()
+	}
+
 	if c.server != nil {
 		c.server.Close()
 		c.server = nil
 	}
+
+	if c.mock != nil {
+		c.mock.AssertExpectations()
+		c.mock = nil
+	}
+
+	return errors.Join(errs...)
 }
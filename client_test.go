@@ -1,12 +1,14 @@
 package httptesting
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strconv"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/golib/assert"
 )
@@ -38,7 +40,7 @@ func Test_New(t *testing.T) {
 	absurl := "https://" + host
 	ws := "ws://" + host
 
-	client := New(host, true)
+	client := New(host, WithTLS())
 	it.Nil(client.server)
 	it.NotEmpty(client.host)
 	it.True(client.isTLS)
@@ -47,6 +49,225 @@ func Test_New(t *testing.T) {
 	it.Equal(ws, client.WebsocketUrl(""))
 }
 
+func Test_NewWithOptions(t *testing.T) {
+	it := assert.New(t)
+
+	method := "GET"
+	uri := "/api/v1/options"
+	server := newMockServer(method, uri, func(w http.ResponseWriter, r *http.Request) {
+		it.Equal(uri, r.URL.Path)
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(r.Header.Get("X-Mock-Client")))
+	})
+
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	client := New(ts.URL,
+		WithBasePath("api/v1/"),
+		WithDefaultHeaders(http.Header{"X-Mock-Client": []string{"httptesting"}}),
+		WithTimeout(time.Second),
+		WithJar(nil),
+	)
+	it.Equal(ts.URL+"/api/v1/options", client.Url("/options"))
+	it.Equal(time.Second, client.timeout)
+	it.Nil(client.jar)
+
+	cookies, err := client.Cookies()
+	it.Nil(err)
+	it.Empty(cookies)
+
+	request := client.New(t)
+	request.Get("/options")
+	request.AssertOK()
+	request.AssertContains("httptesting")
+}
+
+func Test_NewWithTransportConfig(t *testing.T) {
+	it := assert.New(t)
+
+	var addrs []string
+
+	method := "GET"
+	uri := "/transport"
+	server := newMockServer(method, uri, func(w http.ResponseWriter, r *http.Request) {
+		addrs = append(addrs, r.RemoteAddr)
+
+		w.WriteHeader(http.StatusOK)
+	})
+
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	config := DefaultTransportConfig
+	config.ResponseHeaderTimeout = time.Minute
+
+	client := New(ts.URL, WithTransportConfig(config))
+	defer client.Close()
+
+	transport, ok := client.Transport().(*http.Transport)
+	if it.True(ok) {
+		it.Equal(time.Minute, transport.ResponseHeaderTimeout)
+		it.Equal(config.MaxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+	}
+	it.Equal(client.Transport(), client.Transport())
+
+	// it should reuse connection
+	for i := 0; i < 2; i++ {
+		request := client.New(t)
+		request.Get(uri)
+		request.AssertOK()
+	}
+	if it.Len(addrs, 2) {
+		it.Equal(addrs[0], addrs[1])
+	}
+}
+
+func Test_NewWithTransport(t *testing.T) {
+	it := assert.New(t)
+
+	var invoked int
+
+	method := "GET"
+	uri := "/transport"
+	server := newMockServer(method, uri, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(r.Header.Get("X-Filter")))
+	})
+
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	client := New(ts.URL, WithTransport(roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		invoked++
+
+		return http.DefaultTransport.RoundTrip(r)
+	})))
+
+	req, err := http.NewRequest(method, client.Url(uri), nil)
+	it.Nil(err)
+
+	request := client.New(t)
+	request.NewRequest(req, func(r *http.Request) error {
+		r.Header.Set("X-Filter", "filtered")
+		return nil
+	})
+	request.AssertOK()
+	request.AssertContains("filtered")
+	it.Equal(1, invoked)
+}
+
+func Test_NewWithMiddlewares(t *testing.T) {
+	it := assert.New(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/middlewares", http.StatusFound)
+	})
+	mux.HandleFunc("GET /middlewares", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(r.Header.Get("X-Signature")))
+	})
+
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	var (
+		calls []string
+		paths []string
+		built int
+	)
+
+	client := New(ts.URL, WithMiddlewares(
+		func(next http.RoundTripper) http.RoundTripper {
+			built++
+
+			return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				calls = append(calls, "outer")
+				paths = append(paths, r.URL.Path)
+
+				return next.RoundTrip(r)
+			})
+		},
+		RequestMiddleware(func(r *http.Request) error {
+			calls = append(calls, "inner")
+
+			r.Header.Set("X-Signature", r.Header.Get("X-Filter")+"signed")
+			return nil
+		}),
+		ResponseMiddleware(func(resp *http.Response) error {
+			resp.Header.Set("X-Response-Filter", "rewritten")
+			return nil
+		}),
+	))
+
+	req, err := http.NewRequest("GET", client.Url("/redirect"), nil)
+	it.Nil(err)
+
+	// it should invoke middlewares in order after request filters once for each request
+	request := client.New(t)
+	request.NewRequest(req, func(r *http.Request) error {
+		calls = append(calls, "filter")
+
+		r.Header.Set("X-Filter", "filtered-")
+		return nil
+	})
+	request.AssertOK()
+	request.AssertContains("filtered-signed")
+	request.AssertHeader("X-Response-Filter", "rewritten")
+	it.Equal([]string{"filter", "outer", "inner", "filter", "outer", "inner"}, calls)
+	it.Equal([]string{"/redirect", "/middlewares"}, paths)
+
+	// it should build middlewares once for the client
+	request.Get("/middlewares")
+	request.AssertOK()
+	it.Equal(1, built)
+
+	// it should fail with error of response filter
+	client = New(ts.URL, WithMiddlewares(ResponseMiddleware(func(resp *http.Response) error {
+		return errors.New("invalid response")
+	})))
+
+	request = client.New(t).NonFatal()
+	request.Get("/middlewares")
+	if it.NotNil(request.Err()) {
+		it.Contains(request.Err().Error(), "invalid response")
+	}
+}
+
+func Test_FilterTransportWithMiddlewares(t *testing.T) {
+	it := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	var invoked, built int
+
+	transport := NewFilterTransport(nil).Use(func(next http.RoundTripper) http.RoundTripper {
+		built++
+
+		return next
+	}, RequestMiddleware(func(r *http.Request) error {
+		invoked++
+		return nil
+	}))
+
+	client := &http.Client{Transport: transport}
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(ts.URL)
+		if it.Nil(err) {
+			resp.Body.Close()
+		}
+	}
+
+	// it should invoke middlewares once for each request on reused connections
+	it.Equal(3, invoked)
+	it.Equal(1, built)
+}
+
 func Test_NewWithRacy(t *testing.T) {
 	method := "GET"
 	uri := "/request/racy"
@@ -60,7 +281,7 @@ func Test_NewWithRacy(t *testing.T) {
 	ts := httptest.NewServer(server)
 	defer ts.Close()
 
-	client := New(ts.URL, false)
+	client := New(ts.URL)
 
 	var (
 		wg sync.WaitGroup
@@ -90,7 +311,7 @@ func TestTesting_New(t *testing.T) {
 	it := assert.New(t)
 
 	host := "www.example.com"
-	client := New(host, true)
+	client := New(host, WithTLS())
 
 	request := client.New(t)
 	it.Equal(client, request.Client)
@@ -0,0 +1,155 @@
+package httptesting
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/golib/assert"
+)
+
+const (
+	curlInlineBodySize = 4096
+)
+
+// Curl returns an equivalent curl command line of the latest request, which is empty if no request issued.
+// Body larger than 4KB or of binary is written to a temporary file and referenced by --data-binary @file.
+// The temporary file is removed by the next request or cleanup of the test.
+func (r *Request) Curl() string {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	request, body := r.lastRequest, r.lastRequestBody
+	if request == nil {
+		return ""
+	}
+
+	args := []string{"curl"}
+
+	switch request.Method {
+	case "", http.MethodGet:
+		// NOTE: curl sends POST by default for request with data
+		if len(body) > 0 {
+			args = append(args, "-X", http.MethodGet)
+		}
+
+	case http.MethodHead:
+		args = append(args, "--head")
+
+	default:
+		args = append(args, "-X", request.Method)
+	}
+
+	if request.URL.Scheme == "https" && r.Client.insecureTLS {
+		args = append(args, "--insecure")
+	}
+
+	args = append(args, shellQuote(request.URL.String()))
+
+	names := make([]string, 0, len(request.Header))
+	for name := range request.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		switch name {
+		case "Content-Length":
+			// computed by curl
+			continue
+
+		case "Cookie":
+			args = append(args, "-b", shellQuote(strings.Join(request.Header[name], "; ")))
+			continue
+		}
+
+		for _, value := range request.Header[name] {
+			// NOTE: curl sends header of empty value in form of "Name;"
+			if len(value) == 0 {
+				args = append(args, "-H", shellQuote(name+";"))
+			} else {
+				args = append(args, "-H", shellQuote(name+": "+value))
+			}
+		}
+	}
+
+	if len(request.Host) > 0 && request.Host != request.URL.Host {
+		args = append(args, "-H", shellQuote("Host: "+request.Host))
+	}
+
+	if len(body) > 0 {
+		if len(body) <= curlInlineBodySize && utf8.Valid(body) {
+			args = append(args, "--data-raw", shellQuote(string(body)))
+		} else {
+			if len(r.curlBodyFile) == 0 {
+				filename, err := writeCurlBody(body)
+				if err != nil {
+					// NOTE: placeholder of body is not remembered, so the next call retries
+					args = append(args, "--data-binary", shellQuote(fmt.Sprintf("<%d bytes of body: %v>", len(body), err)))
+
+					return strings.Join(args, " ")
+				}
+
+				if cleaner, ok := r.t.(interface{ Cleanup(func()) }); ok {
+					cleaner.Cleanup(func() {
+						os.Remove(filename)
+					})
+				}
+
+				r.curlBodyFile = filename
+			}
+
+			args = append(args, "--data-binary", shellQuote("@"+r.curlBodyFile))
+		}
+	}
+
+	return strings.Join(args, " ")
+}
+
+// reporter returns assert.Testing which appends curl command line of the latest request to failure messages.
+func (r *Request) reporter() assert.Testing {
+	return &curlReporter{
+		t:       r.t,
+		request: r,
+	}
+}
+
+type curlReporter struct {
+	t       TestingT
+	request *Request
+}
+
+func (reporter *curlReporter) Errorf(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+
+	if curl := reporter.request.Curl(); len(curl) > 0 {
+		message = strings.TrimRight(message, "\n") + "\n\r\tCurl:    \t" + curl + "\n"
+	}
+
+	reporter.t.Errorf("%s", message)
+}
+
+func writeCurlBody(body []byte) (string, error) {
+	file, err := os.CreateTemp("", "httptesting-*.body")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	_, err = file.Write(body)
+	if err != nil {
+		os.Remove(file.Name())
+
+		return "", err
+	}
+
+	return file.Name(), nil
+}
+
+// shellQuote quotes s for POSIX shells with single quotes.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
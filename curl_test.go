@@ -0,0 +1,162 @@
+package httptesting
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+func TestRequest_Curl(t *testing.T) {
+	it := assert.New(t)
+
+	server := newMockServer("GET", "/curl", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("curl"))
+	})
+
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	request := New(ts.URL).New(t)
+	it.Empty(request.Curl())
+
+	request.WithHeader("X-Mock-Client", "httptesting")
+	request.WithCookies([]*http.Cookie{{Name: "session", Value: "curl"}})
+	request.Get("/curl?name=it's")
+	it.Equal("curl '"+ts.URL+"/curl?name=it'\\''s' -H 'Content-Type: text/html' -b 'session=curl' -H 'X-Mock-Client: httptesting'", request.Curl())
+
+	request = New(ts.URL).New(t)
+	request.PostJSON("/curl", map[string]string{"name": "httptesting"})
+	it.Equal("curl -X POST '"+ts.URL+"/curl' -H 'Content-Type: application/json' --data-raw '{\"name\":\"httptesting\"}'", request.Curl())
+
+	// it should keep method of GET with body
+	req, err := http.NewRequest("GET", ts.URL+"/curl", strings.NewReader("hello, curl"))
+	if it.Nil(err) {
+		request.NewRequest(req)
+		it.Equal("curl -X GET '"+ts.URL+"/curl' --data-raw 'hello, curl'", request.Curl())
+	}
+
+	// it should reference body of binary with file
+	binary := []byte{0xff, 0xfe, 0x00, 0x01}
+
+	request.Put("/curl", "application/octet-stream", binary)
+
+	curl := request.Curl()
+	it.True(strings.HasPrefix(curl, "curl -X PUT '"+ts.URL+"/curl' -H 'Content-Type: application/octet-stream' --data-binary '@"))
+	it.Equal(curl, request.Curl())
+
+	filename := curlBodyFilename(curl)
+
+	data, err := os.ReadFile(filename)
+	if it.Nil(err) {
+		it.True(bytes.Equal(binary, data))
+	}
+
+	// it should remove file of body by the next request
+	request.Get("/curl")
+
+	_, err = os.Stat(filename)
+	it.True(os.IsNotExist(err))
+
+	// it should append curl to failure messages
+	harness := &mockTesting{}
+
+	request = New(ts.URL).New(harness)
+	request.Delete("/curl", "")
+	it.False(request.AssertStatus(http.StatusNoContent))
+	if it.Len(harness.errors, 1) {
+		it.Contains(harness.errors[0], "\tCurl:    \tcurl -X DELETE '"+ts.URL+"/curl' -H 'Content-Type;'\n")
+	}
+}
+
+func TestRequest_CurlWithCleanup(t *testing.T) {
+	it := assert.New(t)
+
+	server := newMockServer("PUT", "/curl", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	var filename string
+
+	t.Run("PUT /curl", func(t *testing.T) {
+		request := New(ts.URL).New(t)
+		request.Put("/curl", "application/octet-stream", []byte{0xff, 0xfe, 0x00, 0x01})
+
+		filename = curlBodyFilename(request.Curl())
+
+		_, err := os.Stat(filename)
+		it.Nil(err)
+	})
+
+	// it should remove file of body by cleanup of the test
+	_, err := os.Stat(filename)
+	it.True(os.IsNotExist(err))
+}
+
+func TestRequest_CurlWithoutTempDir(t *testing.T) {
+	it := assert.New(t)
+
+	server := newMockServer("PUT", "/curl", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	t.Setenv("TMPDIR", "/nonexistent/httptesting")
+
+	// it should print placeholder of body without remembering it
+	request := New(ts.URL).New(t)
+	request.Put("/curl", "application/octet-stream", []byte{0xff, 0xfe, 0x00, 0x01})
+	it.Contains(request.Curl(), "--data-binary '<4 bytes of body: ")
+	it.NotContains(request.Curl(), "'@")
+	it.Empty(request.curlBodyFile)
+}
+
+func TestRequest_CurlWithRedirect(t *testing.T) {
+	it := assert.New(t)
+
+	server := newMockServer("POST", "/curl", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			http.Redirect(w, r, "/curl/redirected", http.StatusSeeOther)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	request := New(ts.URL).New(t)
+
+	// it should print the redirected request without body
+	request.Post("/curl", "text/plain", []byte("hello, curl"))
+	request.AssertOK()
+	it.Equal("curl '"+ts.URL+"/curl/redirected' -H 'Referer: "+ts.URL+"/curl'", request.Curl())
+
+	// it should be safe for concurrent use
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		for i := 0; i < 10; i++ {
+			request.Curl()
+		}
+	}()
+
+	request.Put("/curl", "application/octet-stream", []byte{0xff, 0xfe, 0x00, 0x01})
+	<-done
+}
+
+func curlBodyFilename(curl string) string {
+	return strings.TrimSuffix(curl[strings.Index(curl, "'@")+2:], "'")
+}
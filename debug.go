@@ -0,0 +1,152 @@
+package httptesting
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	debugRedacted = "[REDACTED]"
+)
+
+var (
+	// DefaultDebugBodyLimit is the max size of body logged by Debug mode without WithDebugBodyLimit option.
+	DefaultDebugBodyLimit = 4096
+
+	debugRedacts = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+)
+
+// Debug turns the request into verbose mode, which logs full request and response of the wire with t.Logf.
+// Values of Authorization, Proxy-Authorization, Cookie and Set-Cookie headers are redacted, and body is
+// truncated by WithDebugBodyLimit option of the client.
+func (r *Request) Debug() *Request {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	r.debug = true
+
+	return r
+}
+
+// dump logs the latest request and its response or error in format of curl -v.
+//
+// NOTE: The caller MUST hold lock of the request.
+func (r *Request) dump() {
+	if r.lastRequest == nil {
+		return
+	}
+
+	limit := r.Client.debugBodyLimit
+
+	var buf bytes.Buffer
+
+	request := r.lastRequest.Clone(context.Background())
+	request.Header = redactHeader(request.Header)
+	request.Body = io.NopCloser(bytes.NewReader(r.lastRequestBody))
+
+	data, err := httputil.DumpRequestOut(request, false)
+	if err != nil {
+		fmt.Fprintf(&buf, "> (dump error: %v)\n", err)
+	} else {
+		writeDump(&buf, "> ", data)
+	}
+	writeDumpBody(&buf, "> ", r.lastRequestBody, limit)
+
+	switch {
+	case r.Response != nil:
+		response := *r.Response
+		response.Header = redactHeader(response.Header)
+		response.Body = io.NopCloser(bytes.NewReader(r.ResponseBody))
+
+		data, err := httputil.DumpResponse(&response, false)
+		if err != nil {
+			fmt.Fprintf(&buf, "< (dump error: %v)\n", err)
+		} else {
+			writeDump(&buf, "< ", data)
+		}
+		writeDumpBody(&buf, "< ", r.ResponseBody, limit)
+
+	case r.err != nil:
+		fmt.Fprintf(&buf, "* %v\n", r.err)
+	}
+
+	r.t.Logf("httptesting: %s %s\n%s", request.Method, request.URL, buf.String())
+}
+
+// redactHeader returns a copy of header with values of sensitive headers redacted.
+func redactHeader(header http.Header) http.Header {
+	header = header.Clone()
+	for _, name := range debugRedacts {
+		if len(header.Values(name)) > 0 {
+			header.Set(name, debugRedacted)
+		}
+	}
+
+	return header
+}
+
+func writeDump(buf *bytes.Buffer, prefix string, data []byte) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		buf.WriteString(prefix)
+		buf.WriteString(scanner.Text())
+		buf.WriteByte('\n')
+	}
+}
+
+func writeDumpBody(buf *bytes.Buffer, prefix string, body []byte, limit int) {
+	if len(body) == 0 {
+		return
+	}
+
+	if isBinary(body) {
+		fmt.Fprintf(buf, "%s(binary body of %d bytes)\n", prefix, len(body))
+		return
+	}
+
+	truncated := 0
+	if limit > 0 && len(body) > limit {
+		// avoid splitting a multi-byte character
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(body[cut]) {
+			cut--
+		}
+
+		truncated = len(body) - cut
+		body = body[:cut]
+	}
+
+	for _, line := range strings.Split(strings.TrimRight(string(body), "\n"), "\n") {
+		buf.WriteString(prefix)
+		buf.WriteString(strings.TrimRight(line, "\r"))
+		buf.WriteByte('\n')
+	}
+
+	if truncated > 0 {
+		fmt.Fprintf(buf, "%s(truncated %d bytes)\n", prefix, truncated)
+	}
+}
+
+// isBinary reports whether body is not readable text, which is either invalid UTF-8 or containing
+// control characters other than whitespace.
+func isBinary(body []byte) bool {
+	if !utf8.Valid(body) {
+		return true
+	}
+
+	for _, c := range string(body) {
+		if unicode.IsControl(c) && !unicode.IsSpace(c) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,72 @@
+package httptesting
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+func TestRequest_Debug(t *testing.T) {
+	it := assert.New(t)
+
+	server := newMockServer("POST", "/debug", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "secret"})
+
+		switch r.URL.Query().Get("format") {
+		case "binary":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte{0x00, 0x01, 0x02})
+
+		default:
+			w.Header().Set("Content-Type", "text/plain")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(strings.Repeat("a", 32) + "\n" + strings.Repeat("b", 32)))
+		}
+	})
+
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	harness := &mockTesting{}
+
+	request := New(ts.URL, WithDebugBodyLimit(40)).New(harness).Debug()
+	request.WithHeader("Authorization", "Bearer secret")
+	request.WithCookies([]*http.Cookie{{Name: "session", Value: "secret"}})
+	request.Post("/debug", "text/plain", []byte("hello, debug"))
+	if it.Len(harness.logs, 1) {
+		log := harness.logs[0]
+		it.Contains(log, "httptesting: POST "+ts.URL+"/debug\n")
+		it.Contains(log, "> POST /debug HTTP/1.1\n")
+		it.Contains(log, "> Authorization: [REDACTED]")
+		it.Contains(log, "> Cookie: [REDACTED]")
+		it.Contains(log, "> Content-Length: 12")
+		it.Contains(log, "> hello, debug\n")
+		it.Contains(log, "< HTTP/1.1 200 OK")
+		it.Contains(log, "< Set-Cookie: [REDACTED]")
+		it.Contains(log, "< "+strings.Repeat("a", 32)+"\n< bbbbbbb\n< (truncated 25 bytes)\n")
+		it.NotContains(log, "secret")
+	}
+
+	// it should work with client of verbose mode
+	harness = &mockTesting{}
+
+	request = New(ts.URL, WithVerbose()).New(harness)
+	request.Post("/debug?format=binary", "text/plain", nil)
+	if it.Len(harness.logs, 1) {
+		it.Contains(harness.logs[0], "< (binary body of 3 bytes)\n")
+	}
+
+	// it should log error
+	harness = &mockTesting{}
+
+	request = New("127.0.0.1:1", WithVerbose()).New(harness).NonFatal()
+	request.Get("/debug")
+	if it.Len(harness.logs, 1) {
+		it.Contains(harness.logs[0], "> GET /debug HTTP/1.1")
+		it.Contains(harness.logs[0], "* ")
+	}
+}
@@ -0,0 +1,148 @@
+package httptesting
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"mime"
+	"strings"
+
+	"github.com/golib/assert"
+)
+
+const (
+	excerptSize = 64
+)
+
+// DisallowUnknownFields makes DecodeJSON and Decode fail when the response body contains
+// keys which do not match any exported fields of the destination.
+func (r *Request) DisallowUnknownFields() *Request {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	r.strictDecoding = true
+
+	return r
+}
+
+// DecodeJSON decodes the response body into v with encoding/json.
+func (r *Request) DecodeJSON(v interface{}) bool {
+	if !r.assertResponse() {
+		return false
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(r.ResponseBody))
+	if r.strictDecoding {
+		decoder.DisallowUnknownFields()
+	}
+
+	err := decoder.Decode(v)
+	if err != nil {
+		var offset int64
+
+		var (
+			syntaxErr *json.SyntaxError
+			typeErr   *json.UnmarshalTypeError
+		)
+		switch {
+		case errors.As(err, &syntaxErr):
+			offset = syntaxErr.Offset
+
+		case errors.As(err, &typeErr):
+			offset = typeErr.Offset
+
+		default:
+			offset = decoder.InputOffset()
+		}
+
+		return r.failDecode("JSON", v, err, offset)
+	}
+
+	// NOTE: Decode stops at the end of the first value, which accepts trailing data
+	offset := decoder.InputOffset()
+	if _, err := decoder.Token(); err != io.EOF {
+		return r.failDecode("JSON", v, errors.New("invalid character after top-level value"), offset)
+	}
+
+	return true
+}
+
+// DecodeXML decodes the response body into v with encoding/xml.
+func (r *Request) DecodeXML(v interface{}) bool {
+	if !r.assertResponse() {
+		return false
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(r.ResponseBody))
+
+	err := decoder.Decode(v)
+	if err != nil {
+		return r.failDecode("XML", v, err, decoder.InputOffset())
+	}
+
+	return true
+}
+
+// Decode decodes the response body into v with decoder chosen by Content-Type header of the response,
+// both JSON and XML are supported.
+func (r *Request) Decode(v interface{}) bool {
+	if !r.assertResponse() {
+		return false
+	}
+
+	contentType := r.Response.Header.Get("Content-Type")
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/json", mediaType == "text/json", strings.HasSuffix(mediaType, "+json"):
+		return r.DecodeJSON(v)
+
+	case mediaType == "application/xml", mediaType == "text/xml", strings.HasSuffix(mediaType, "+xml"):
+		return r.DecodeXML(v)
+	}
+
+	return assert.Fail(r.reporter(), "Response header: Content-Type of "+contentType+" (*unsupported)",
+		"Expected response of JSON or XML content type for decoding %T, but got %q",
+		v,
+		contentType,
+	)
+}
+
+func (r *Request) failDecode(format string, v interface{}, err error, offset int64) bool {
+	return assert.Fail(r.reporter(), "Response body: "+excerpt(r.ResponseBody, offset),
+		"Expected response body decoded into %T as %s, but got %v",
+		v,
+		format,
+		err,
+	)
+}
+
+// excerpt returns a readable part of body around offset.
+func excerpt(body []byte, offset int64) string {
+	if len(body) == 0 {
+		return "(empty)"
+	}
+
+	start, end := offset-excerptSize, offset+excerptSize
+	if start < 0 {
+		start = 0
+	}
+	if end > int64(len(body)) {
+		end = int64(len(body))
+	}
+	if start > end {
+		start = end
+	}
+
+	s := string(body[start:end])
+	if start > 0 {
+		s = "..." + s
+	}
+	if end < int64(len(body)) {
+		s += "..."
+	}
+
+	return s
+}
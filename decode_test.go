@@ -0,0 +1,128 @@
+package httptesting
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+func TestRequest_Decode(t *testing.T) {
+	type user struct {
+		XMLName xml.Name `json:"-" xml:"user"`
+		Name    string   `json:"name" xml:"name"`
+		Age     int      `json:"age" xml:"age"`
+	}
+
+	it := assert.New(t)
+
+	server := newMockServer("GET", "/decode", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/decode/json":
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Write([]byte(`{"name":"httptesting","age":3,"email":"httptesting@example.com"}`))
+
+		case "/decode/xml":
+			w.Header().Set("Content-Type", "application/xml")
+			w.Write([]byte(`<user><name>httptesting</name><age>3</age></user>`))
+
+		case "/decode/invalid":
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.Write([]byte(`{"name":"httptesting","age":"three"}`))
+
+		case "/decode/trailing":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"name":"httptesting","age":3} garbage`))
+
+		default:
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("httptesting"))
+		}
+	})
+
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	client := New(ts.URL)
+
+	// it should decode JSON
+	var data user
+
+	request := client.New(t)
+	request.GetJSON("/decode/json")
+	if it.True(request.DecodeJSON(&data)) {
+		it.Equal("httptesting", data.Name)
+		it.Equal(3, data.Age)
+	}
+
+	data = user{}
+	if it.True(request.Decode(&data)) {
+		it.Equal("httptesting", data.Name)
+	}
+
+	// it should decode XML
+	data = user{}
+
+	request = client.New(t)
+	request.GetXML("/decode/xml")
+	if it.True(request.Decode(&data)) {
+		it.Equal("httptesting", data.Name)
+		it.Equal(3, data.Age)
+	}
+
+	// it should fail with unknown fields
+	harness := &mockTesting{}
+
+	request = client.New(harness).DisallowUnknownFields()
+	request.GetJSON("/decode/json")
+	it.False(request.DecodeJSON(&data))
+	if it.Len(harness.errors, 1) {
+		it.Contains(harness.errors[0], `unknown field "email"`)
+	}
+
+	// it should fail with excerpt of response body
+	harness = &mockTesting{}
+
+	request = client.New(harness)
+	request.GetJSON("/decode/invalid")
+	it.False(request.Decode(&data))
+	if it.Len(harness.errors, 1) {
+		it.Contains(harness.errors[0], `{"name":"httptesting","age":"three"`)
+	}
+
+	// it should fail with trailing data
+	harness = &mockTesting{}
+
+	request = client.New(harness)
+	request.GetJSON("/decode/trailing")
+	it.False(request.DecodeJSON(&data))
+	if it.Len(harness.errors, 1) {
+		it.Contains(harness.errors[0], "invalid character after top-level value")
+	}
+
+	// it should fail with unsupported content type
+	harness = &mockTesting{}
+
+	request = client.New(harness)
+	request.Get("/decode/text")
+	it.False(request.Decode(&data))
+	if it.Len(harness.errors, 1) {
+		it.Contains(harness.errors[0], "text/plain")
+	}
+}
+
+func Test_excerpt(t *testing.T) {
+	it := assert.New(t)
+
+	body := make([]byte, 3*excerptSize)
+	for i := range body {
+		body[i] = 'a'
+	}
+
+	it.Equal("(empty)", excerpt(nil, 0))
+	it.Equal(string(body[:excerptSize])+"...", excerpt(body, 0))
+	it.Equal("..."+string(body[2*excerptSize:]), excerpt(body, int64(len(body))))
+	it.Equal("..."+string(body[1:2*excerptSize+1])+"...", excerpt(body, excerptSize+1))
+}
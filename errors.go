@@ -0,0 +1,62 @@
+package httptesting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// Error records a failure of Request and the operation which caused it.
+// The underlying error can be inspected with errors.Is and errors.As.
+type Error struct {
+	Op     string
+	Method string
+	URL    string
+	Err    error
+}
+
+func (e *Error) Error() string {
+	switch {
+	case e.DeadlineExceeded():
+		return fmt.Sprintf("httptesting: %s:%s %s: deadline exceeded: %v", e.Op, e.Method, e.URL, e.Err)
+
+	case e.Canceled():
+		return fmt.Sprintf("httptesting: %s:%s %s: canceled: %v", e.Op, e.Method, e.URL, e.Err)
+	}
+
+	if ok, certErr := certificateError(e.Err); ok {
+		return fmt.Sprintf("httptesting: %s:%s %s: TLS certificate verification failed: %v", e.Op, e.Method, e.URL, certErr)
+	}
+
+	return fmt.Sprintf("httptesting: %s:%s %s: %v", e.Op, e.Method, e.URL, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Timeout reports whether the error is caused by timeout.
+func (e *Error) Timeout() bool {
+	var netErr net.Error
+
+	return errors.As(e.Err, &netErr) && netErr.Timeout()
+}
+
+// DeadlineExceeded reports whether the error is caused by deadline of the request context.
+func (e *Error) DeadlineExceeded() bool {
+	return errors.Is(e.Err, context.DeadlineExceeded)
+}
+
+// Canceled reports whether the error is caused by cancellation of the request context.
+func (e *Error) Canceled() bool {
+	return errors.Is(e.Err, context.Canceled)
+}
+
+// Certificate reports whether the error is caused by server certificate verification.
+func (e *Error) Certificate() bool {
+	ok, _ := certificateError(e.Err)
+
+	return ok
+}
@@ -0,0 +1,341 @@
+package httptesting
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Fault defines a failure injected into a request, next is the transport serving the request without the fault.
+// The rng is seeded by NewFaultInjector and safe for concurrent use.
+type Fault func(rng *rand.Rand, r *http.Request, next http.RoundTripper) (*http.Response, error)
+
+// FaultInjector injects faults into requests matching rules with probabilities, which is
+// used by Client with WithFaults option.
+//
+// NOTE: Faults are reproducible with the same seed only if requests are issued sequentially.
+type FaultInjector struct {
+	mux      sync.Mutex
+	rng      *rand.Rand
+	rules    []*faultRule
+	injected int
+}
+
+type faultRule struct {
+	method      string
+	segments    []string
+	probability float64
+	faults      []Fault
+}
+
+// NewFaultInjector returns a *FaultInjector with random generator of the seed given.
+func NewFaultInjector(seed int64) *FaultInjector {
+	return &FaultInjector{
+		rng: rand.New(&lockedSource{
+			src: rand.NewSource(seed),
+		}),
+	}
+}
+
+// On adds a rule which injects faults into requests of the method and pattern with probability in range of [0, 1].
+// Both method and pattern accept "*" for any, and segments of pattern in form of {name} match any value.
+// Faults of all rules fired are applied to a request in order of adding.
+func (injector *FaultInjector) On(method, pattern string, probability float64, faults ...Fault) *FaultInjector {
+	rule := &faultRule{
+		method:      strings.ToUpper(method),
+		probability: probability,
+		faults:      faults,
+	}
+	if pattern != "*" {
+		rule.segments = splitPath(pattern)
+	}
+
+	injector.mux.Lock()
+	injector.rules = append(injector.rules, rule)
+	injector.mux.Unlock()
+
+	return injector
+}
+
+// Injected returns count of requests injected with faults.
+func (injector *FaultInjector) Injected() int {
+	injector.mux.Lock()
+	defer injector.mux.Unlock()
+
+	return injector.injected
+}
+
+// Transport returns a http.RoundTripper which injects faults into requests before delegating to transport.
+func (injector *FaultInjector) Transport(transport http.RoundTripper) http.RoundTripper {
+	return &faultTransport{
+		injector:  injector,
+		transport: transport,
+	}
+}
+
+// fire rolls probabilities of rules matching the request, and returns faults of rules fired.
+func (injector *FaultInjector) fire(r *http.Request) []Fault {
+	injector.mux.Lock()
+	defer injector.mux.Unlock()
+
+	var faults []Fault
+	for _, rule := range injector.rules {
+		if rule.method != "*" && rule.method != r.Method {
+			continue
+		}
+
+		if rule.segments != nil {
+			if _, ok := matchPath(rule.segments, r.URL.Path); !ok {
+				continue
+			}
+		}
+
+		if injector.rng.Float64() < rule.probability {
+			faults = append(faults, rule.faults...)
+		}
+	}
+
+	if len(faults) > 0 {
+		injector.injected++
+	}
+
+	return faults
+}
+
+type faultTransport struct {
+	injector  *FaultInjector
+	transport http.RoundTripper
+}
+
+func (transport *faultTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	rng := transport.injector.rng
+
+	next := transport.transport
+	faults := transport.injector.fire(r)
+	for i := len(faults) - 1; i >= 0; i-- {
+		fault, inner := faults[i], next
+
+		next = roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			return fault(rng, r, inner)
+		})
+	}
+
+	return next.RoundTrip(r)
+}
+
+func (transport *faultTransport) CloseIdleConnections() {
+	if closer, ok := transport.transport.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+// FaultLatency delays the request for d before sending it, which respects deadline of the request.
+func FaultLatency(d time.Duration) Fault {
+	return func(rng *rand.Rand, r *http.Request, next http.RoundTripper) (*http.Response, error) {
+		if err := sleepContext(r.Context(), d); err != nil {
+			return nil, err
+		}
+
+		return next.RoundTrip(r)
+	}
+}
+
+// FaultReset fails the request with connection reset by peer without sending it.
+func FaultReset() Fault {
+	return func(rng *rand.Rand, r *http.Request, next http.RoundTripper) (*http.Response, error) {
+		return nil, &net.OpError{
+			Op:  "read",
+			Net: "tcp",
+			Err: os.NewSyscallError("read", syscall.ECONNRESET),
+		}
+	}
+}
+
+// FaultTruncateBody cuts response body off after n bytes, reading beyond which fails with io.ErrUnexpectedEOF.
+func FaultTruncateBody(n int) Fault {
+	return func(rng *rand.Rand, r *http.Request, next http.RoundTripper) (*http.Response, error) {
+		resp, err := next.RoundTrip(r)
+		if err != nil {
+			return resp, err
+		}
+
+		resp.Body = &truncatedBody{
+			ReadCloser: resp.Body,
+			remain:     n,
+		}
+
+		return resp, nil
+	}
+}
+
+// FaultSlowBody delivers response body byte by byte with delay between bytes.
+func FaultSlowBody(delay time.Duration) Fault {
+	return func(rng *rand.Rand, r *http.Request, next http.RoundTripper) (*http.Response, error) {
+		resp, err := next.RoundTrip(r)
+		if err != nil {
+			return resp, err
+		}
+
+		resp.Body = &slowBody{
+			ReadCloser: resp.Body,
+			ctx:        r.Context(),
+			delay:      delay,
+		}
+
+		return resp, nil
+	}
+}
+
+// FaultStatus responds the request with status code picked from codes randomly without sending it.
+// It picks from 500, 502, 503 and 504 if no codes given.
+func FaultStatus(codes ...int) Fault {
+	if len(codes) == 0 {
+		codes = []int{
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		}
+	}
+
+	return func(rng *rand.Rand, r *http.Request, next http.RoundTripper) (*http.Response, error) {
+		code := codes[rng.Intn(len(codes))]
+		body := http.StatusText(code)
+
+		return &http.Response{
+			Status:     strconv.Itoa(code) + " " + body,
+			StatusCode: code,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header: http.Header{
+				"Content-Type": []string{"text/plain; charset=utf-8"},
+			},
+			Body:          io.NopCloser(bytes.NewReader([]byte(body))),
+			ContentLength: int64(len(body)),
+			Request:       r,
+		}, nil
+	}
+}
+
+// FaultDNS fails the request with host not found without sending it.
+func FaultDNS() Fault {
+	return func(rng *rand.Rand, r *http.Request, next http.RoundTripper) (*http.Response, error) {
+		return nil, &net.OpError{
+			Op:  "dial",
+			Net: "tcp",
+			Err: &net.DNSError{
+				Err:        "no such host",
+				Name:       r.URL.Hostname(),
+				IsNotFound: true,
+			},
+		}
+	}
+}
+
+// FaultTLS fails the request with TLS handshake failure without sending it.
+func FaultTLS() Fault {
+	return func(rng *rand.Rand, r *http.Request, next http.RoundTripper) (*http.Response, error) {
+		return nil, &net.OpError{
+			Op:  "remote error",
+			Net: "tcp",
+			Err: tls.AlertError(40), // handshake_failure
+		}
+	}
+}
+
+// lockedSource is a rand.Source safe for concurrent use.
+type lockedSource struct {
+	mux sync.Mutex
+	src rand.Source
+}
+
+func (source *lockedSource) Int63() int64 {
+	source.mux.Lock()
+	defer source.mux.Unlock()
+
+	return source.src.Int63()
+}
+
+func (source *lockedSource) Seed(seed int64) {
+	source.mux.Lock()
+	defer source.mux.Unlock()
+
+	source.src.Seed(seed)
+}
+
+type truncatedBody struct {
+	io.ReadCloser
+	remain int
+}
+
+func (body *truncatedBody) Read(p []byte) (int, error) {
+	if body.remain <= 0 {
+		// NOTE: body arrived whole if it has no bytes left
+		var b [1]byte
+		for {
+			n, err := body.ReadCloser.Read(b[:])
+			if n > 0 {
+				return 0, io.ErrUnexpectedEOF
+			}
+
+			if err == io.EOF {
+				return 0, io.EOF
+			}
+
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	if len(p) > body.remain {
+		p = p[:body.remain]
+	}
+
+	n, err := body.ReadCloser.Read(p)
+	body.remain -= n
+
+	return n, err
+}
+
+type slowBody struct {
+	io.ReadCloser
+	ctx   context.Context
+	delay time.Duration
+}
+
+func (body *slowBody) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	if err := sleepContext(body.ctx, body.delay); err != nil {
+		return 0, err
+	}
+
+	return body.ReadCloser.Read(p[:1])
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
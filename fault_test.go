@@ -0,0 +1,141 @@
+package httptesting
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/golib/assert"
+)
+
+func TestNewFaultInjector(t *testing.T) {
+	it := assert.New(t)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("Hello, httptesting!"))
+	})
+
+	injector := NewFaultInjector(42).
+		On("GET", "/latency", 1, FaultLatency(100*time.Millisecond)).
+		On("GET", "/reset", 1, FaultReset()).
+		On("GET", "/truncate", 1, FaultTruncateBody(5)).
+		On("GET", "/truncate/whole", 1, FaultTruncateBody(len("Hello, httptesting!"))).
+		On("GET", "/slow", 1, FaultSlowBody(time.Millisecond)).
+		On("GET", "/status", 1, FaultStatus(http.StatusServiceUnavailable)).
+		On("GET", "/dns", 1, FaultDNS()).
+		On("GET", "/tls", 1, FaultTLS()).
+		On("*", "/never/{id}", 0, FaultReset())
+
+	client := NewServer(handler, WithFaults(injector), WithRecordRequests(0))
+	defer client.Close()
+
+	// it should delay the request
+	request := client.New(t).NonFatal()
+	request.WithTimeout(20 * time.Millisecond).Get("/latency")
+	if it.NotNil(request.Err()) {
+		it.True(errors.Is(request.Err(), context.DeadlineExceeded))
+	}
+
+	request = client.New(t)
+	request.WithTimeout(time.Second).Get("/latency")
+	request.AssertOK()
+	request.AssertLatencyUnder(time.Second)
+
+	// it should reset connection
+	request = client.New(t).NonFatal()
+	request.Get("/reset")
+	if it.NotNil(request.Err()) {
+		it.True(errors.Is(request.Err(), syscall.ECONNRESET))
+	}
+
+	// it should truncate response body
+	request.Get("/truncate")
+	if it.NotNil(request.Err()) {
+		it.True(errors.Is(request.Err(), io.ErrUnexpectedEOF))
+	}
+
+	// it should not fail body of exact length
+	request.Get("/truncate/whole")
+	if it.Nil(request.Err()) {
+		request.AssertOK()
+		request.AssertContains("Hello, httptesting!")
+	}
+
+	// it should deliver response body slowly
+	request = client.New(t)
+	request.Get("/slow")
+	request.AssertOK()
+	request.AssertContains("Hello, httptesting!")
+
+	// it should respond with status
+	request.Get("/status")
+	request.AssertStatus(http.StatusServiceUnavailable)
+	request.AssertContains("Service Unavailable")
+
+	// it should fail with DNS error
+	request = client.New(t).NonFatal()
+	request.Get("/dns")
+	if it.NotNil(request.Err()) {
+		var dnsErr *net.DNSError
+		if it.True(errors.As(request.Err(), &dnsErr)) {
+			it.True(dnsErr.IsNotFound)
+			it.Equal("127.0.0.1", dnsErr.Name)
+		}
+	}
+
+	// it should fail with TLS handshake error
+	request.Get("/tls")
+	if it.NotNil(request.Err()) {
+		var alertErr tls.AlertError
+		it.True(errors.As(request.Err(), &alertErr))
+	}
+
+	// it should never inject faults with probability of 0
+	request = client.New(t)
+	request.Get("/never/1")
+	request.AssertOK()
+
+	it.Equal(9, injector.Injected())
+	request.AssertReceivedCount(5)
+}
+
+func TestFaultInjectorWithSeed(t *testing.T) {
+	it := assert.New(t)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	run := func(seed int64) string {
+		injector := NewFaultInjector(seed).
+			On("*", "*", 0.5, FaultStatus())
+
+		client := NewServer(handler, WithFaults(injector))
+		defer client.Close()
+
+		var statuses []string
+
+		request := client.New(t)
+		for i := 0; i < 20; i++ {
+			request.Get("/")
+
+			statuses = append(statuses, request.Response.Status[:3])
+		}
+
+		return strings.Join(statuses, ",")
+	}
+
+	// it should inject the same faults with the same seed
+	statuses := run(1)
+	it.Equal(statuses, run(1))
+	it.NotEqual(statuses, run(2))
+	it.Contains(statuses, "200")
+	it.Contains(statuses, "50")
+}
@@ -3,13 +3,18 @@ module github.com/dolab/httptesting
 go 1.24.1
 
 require (
+	github.com/antchfx/xmlquery v1.5.0
+	github.com/antchfx/xpath v1.3.5
 	github.com/golib/assert v1.7.0
+	github.com/santhosh-tekuri/jsonschema/v6 v6.0.2
 	golang.org/x/net v0.37.0
+	golang.org/x/text v0.23.0
 )
 
 require (
 	github.com/buger/jsonparser v1.1.1 // indirect
 	github.com/dolab/types v1.0.0 // indirect
+	github.com/golang/groupcache v0.0.0-20210331224755-41bb18bfe9da // indirect
 	github.com/kr/pretty v0.3.1 // indirect
 	github.com/kr/text v0.2.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
@@ -0,0 +1,174 @@
+package httptesting
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/golib/assert"
+)
+
+// GoldenUpdateEnv is name of the environment variable which rewrites golden files of AssertGolden when it is true,
+// e.g. HTTPTESTING_UPDATE=1 go test ./...
+const GoldenUpdateEnv = "HTTPTESTING_UPDATE"
+
+var (
+	goldenDateRegexp = regexp.MustCompile(
+		`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?` + // RFC 3339
+			`|(Mon|Tue|Wed|Thu|Fri|Sat|Sun), \d{2} (Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec) \d{4} \d{2}:\d{2}:\d{2} GMT`, // RFC 1123
+	)
+	goldenUUIDRegexp = regexp.MustCompile(`(?i)[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+)
+
+// Golden defines snapshot of response stored in golden file.
+type Golden struct {
+	Status int
+	Header http.Header
+	Body   []byte
+
+	filtered bool
+}
+
+// GoldenNormalizer defines a callback for normalizing snapshot before comparing and storing,
+// which keeps snapshot stable across runs.
+type GoldenNormalizer func(golden *Golden)
+
+// GoldenHeaders keeps only headers named in snapshot. Only Content-Type header is kept by default.
+func GoldenHeaders(names ...string) GoldenNormalizer {
+	return func(golden *Golden) {
+		header := http.Header{}
+		for _, name := range names {
+			if values := golden.Header.Values(name); len(values) > 0 {
+				header[http.CanonicalHeaderKey(name)] = values
+			}
+		}
+
+		golden.Header = header
+		golden.filtered = true
+	}
+}
+
+// GoldenJSON pretty prints body of JSON with sorted keys.
+func GoldenJSON() GoldenNormalizer {
+	return func(golden *Golden) {
+		var data interface{}
+		if err := json.Unmarshal(golden.Body, &data); err != nil {
+			return
+		}
+
+		if b, err := json.MarshalIndent(data, "", "  "); err == nil {
+			golden.Body = b
+		}
+	}
+}
+
+// GoldenScrub replaces all matches of the regular expression within header values and body with replacement.
+func GoldenScrub(re *regexp.Regexp, replacement string) GoldenNormalizer {
+	return func(golden *Golden) {
+		for _, values := range golden.Header {
+			for i, value := range values {
+				values[i] = re.ReplaceAllString(value, replacement)
+			}
+		}
+
+		golden.Body = re.ReplaceAll(golden.Body, []byte(replacement))
+	}
+}
+
+// GoldenScrubDates replaces dates in RFC 3339 and RFC 1123 formats with <date>.
+func GoldenScrubDates() GoldenNormalizer {
+	return GoldenScrub(goldenDateRegexp, "<date>")
+}
+
+// GoldenScrubUUIDs replaces UUIDs with <uuid>.
+func GoldenScrubUUIDs() GoldenNormalizer {
+	return GoldenScrub(goldenUUIDRegexp, "<uuid>")
+}
+
+// Bytes returns content of the snapshot for golden file.
+func (golden *Golden) Bytes() []byte {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "%d %s\n", golden.Status, http.StatusText(golden.Status))
+
+	names := make([]string, 0, len(golden.Header))
+	for name := range golden.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, value := range golden.Header[name] {
+			fmt.Fprintf(&buf, "%s: %s\n", name, value)
+		}
+	}
+	buf.WriteString("\n")
+	buf.Write(golden.Body)
+
+	return buf.Bytes()
+}
+
+// AssertGolden asserts that status, headers and body of the response match the golden file
+// testdata/<name>.golden, the golden file is rewritten when running tests with HTTPTESTING_UPDATE=1.
+func (r *Request) AssertGolden(name string, normalizers ...GoldenNormalizer) bool {
+	if !r.assertResponse() {
+		return false
+	}
+
+	golden := &Golden{
+		Status: r.Response.StatusCode,
+		Header: r.Response.Header.Clone(),
+		Body:   append([]byte(nil), r.ResponseBody...),
+	}
+	for _, normalizer := range normalizers {
+		normalizer(golden)
+	}
+	if !golden.filtered {
+		GoldenHeaders("Content-Type")(golden)
+	}
+
+	filename := filepath.Join("testdata", filepath.FromSlash(name)+".golden")
+
+	actual := golden.Bytes()
+	if updateGolden() {
+		err := os.MkdirAll(filepath.Dir(filename), 0755)
+		if err == nil {
+			err = os.WriteFile(filename, actual, 0644)
+		}
+		if err != nil {
+			return assert.Fail(r.reporter(), "Golden file: "+filename+" (*unwritable)",
+				"Expected golden file updated, but got %v",
+				err,
+			)
+		}
+
+		r.t.Logf("httptesting: AssertGolden: updated golden file %s\n", filename)
+		return true
+	}
+
+	expected, err := os.ReadFile(filename)
+	if err != nil {
+		return assert.Fail(r.reporter(), "Golden file: "+filename+" (*required)",
+			"Expected golden file exists, run tests with "+GoldenUpdateEnv+"=1 to create it, but got %v",
+			err,
+		)
+	}
+
+	return assert.Equal(r.reporter(), string(expected), string(actual),
+		"Expected response matches golden file %s, run tests with "+GoldenUpdateEnv+"=1 to rewrite it",
+		filename,
+	)
+}
+
+// updateGolden reports whether golden files should be rewritten, which is read for each assertion.
+func updateGolden() bool {
+	update, _ := strconv.ParseBool(os.Getenv(GoldenUpdateEnv))
+
+	return update
+}
@@ -0,0 +1,87 @@
+package httptesting
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golib/assert"
+)
+
+func TestRequest_AssertGolden(t *testing.T) {
+	server := newMockServer("GET", "/golden", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Id", "0b3d5a6e-4c0f-4a8e-9f43-2b8c7e9d1f00")
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"user":{"name":"httptesting","id":"` + "9c6e1f52-7d1b-4b36-8c7a-5b2f0e4d3a21" + `"},"created_at":"` + time.Now().Format(time.RFC3339Nano) + `"}`))
+	})
+
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	request := New(ts.URL).New(t)
+	request.GetJSON("/golden")
+	request.AssertOK()
+	request.AssertGolden("golden/user",
+		GoldenHeaders("Content-Type", "X-Request-Id", "Last-Modified"),
+		GoldenJSON(),
+		GoldenScrubDates(),
+		GoldenScrubUUIDs(),
+	)
+}
+
+func TestRequest_AssertGoldenWithUpdate(t *testing.T) {
+	it := assert.New(t)
+
+	body := "httptesting"
+
+	server := newMockServer("GET", "/golden", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(body))
+	})
+
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	t.Chdir(t.TempDir())
+
+	// it should fail without golden file
+	harness := &mockTesting{}
+
+	request := New(ts.URL).New(harness)
+	request.Get("/golden")
+	it.False(request.AssertGolden("text"))
+	if it.Len(harness.errors, 1) {
+		it.Contains(harness.errors[0], GoldenUpdateEnv+"=1")
+	}
+
+	// it should create golden file with HTTPTESTING_UPDATE=1
+	t.Setenv(GoldenUpdateEnv, "1")
+
+	it.True(request.AssertGolden("text"))
+
+	b, err := os.ReadFile(filepath.Join("testdata", "text.golden"))
+	if it.Nil(err) {
+		it.Equal("200 OK\nContent-Type: text/plain\n\nhttptesting", string(b))
+	}
+
+	t.Setenv(GoldenUpdateEnv, "")
+
+	// it should compare with golden file
+	harness = &mockTesting{}
+
+	request = New(ts.URL).New(harness)
+	request.Get("/golden")
+	it.True(request.AssertGolden("text"))
+
+	body = "mismatched"
+
+	request.Get("/golden")
+	it.False(request.AssertGolden("text"))
+	it.Len(harness.errors, 1)
+}
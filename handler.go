@@ -0,0 +1,152 @@
+package httptesting
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+)
+
+const (
+	handlerHost       = "localhost"
+	handlerRemoteAddr = "127.0.0.1:1234"
+)
+
+// NewHandler returns an initialized *Client which dispatches requests straight into handler in memory,
+// without opening any socket. Cookies, redirects, inbound requests recording and verification of
+// client certificates work as NewServer.
+//
+// NOTE: NewWebsocket is not supported by the client, and WithTransport option panics. Requests are
+// dispatched without connections, so no phase except Total of Timings is measured, and
+// AssertConnectionReused always fails.
+func NewHandler(handler http.Handler, opts ...Option) *Client {
+	client := newClient(false, opts)
+	if client.transport != nil {
+		panic("httptesting: NewHandler: WithTransport option is not supported")
+	}
+
+	client.inMemory = true
+	client.host = handlerHost
+	client.transport = &handlerTransport{
+		client:  client,
+		handler: client.serve(handler),
+	}
+
+	return client
+}
+
+// handlerTransport defines a http.RoundTripper which serves requests by handler with httptest.ResponseRecorder.
+type handlerTransport struct {
+	client  *Client
+	handler http.Handler
+}
+
+func (transport *handlerTransport) RoundTrip(r *http.Request) (resp *http.Response, err error) {
+	// mimic request received by server
+	request := r.Clone(r.Context())
+	request.RequestURI = r.URL.RequestURI()
+	request.RemoteAddr = handlerRemoteAddr
+	request.Proto, request.ProtoMajor, request.ProtoMinor = "HTTP/1.1", 1, 1
+	if len(request.Host) == 0 {
+		request.Host = r.URL.Host
+	}
+	if request.Body == nil {
+		request.Body = http.NoBody
+	}
+	if r.URL.Scheme == "https" {
+		request.TLS, err = transport.connectionState(request.Host)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	defer func() {
+		if e := recover(); e != nil {
+			resp = nil
+			err = fmt.Errorf("handler panic serving %s %s: %v", r.Method, r.URL.RequestURI(), e)
+		}
+	}()
+
+	recorder := httptest.NewRecorder()
+
+	transport.handler.ServeHTTP(recorder, request)
+
+	resp = recorder.Result()
+	resp.Request = r
+	if r.Method == http.MethodHead {
+		resp.Body = http.NoBody
+	}
+
+	return resp, nil
+}
+
+// connectionState returns TLS state of a completed handshake, which presents chain of the first
+// client certificate given by WithClientCert option. As a real handshake, the certificate is requested
+// and verified against CAs according to policy given by WithClientAuth option.
+func (transport *handlerTransport) connectionState(host string) (*tls.ConnectionState, error) {
+	client := transport.client
+
+	state := &tls.ConnectionState{
+		Version:           tls.VersionTLS13,
+		HandshakeComplete: true,
+		ServerName:        host,
+	}
+
+	if client.clientAuth == tls.NoClientCert {
+		return state, nil
+	}
+
+	var certs []*x509.Certificate
+	if len(client.clientCerts) > 0 {
+		for _, der := range client.clientCerts[0].Certificate {
+			cert, err := x509.ParseCertificate(der)
+			if err != nil {
+				return nil, handshakeError(42) // bad_certificate
+			}
+
+			certs = append(certs, cert)
+		}
+	}
+
+	if len(certs) == 0 {
+		switch client.clientAuth {
+		case tls.RequireAnyClientCert, tls.RequireAndVerifyClientCert:
+			return nil, handshakeError(116) // certificate_required
+		}
+
+		return state, nil
+	}
+
+	if client.clientAuth >= tls.VerifyClientCertIfGiven {
+		opts := x509.VerifyOptions{
+			Roots:         client.clientCAs,
+			Intermediates: x509.NewCertPool(),
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		}
+		for _, cert := range certs[1:] {
+			opts.Intermediates.AddCert(cert)
+		}
+
+		chains, err := certs[0].Verify(opts)
+		if err != nil {
+			return nil, handshakeError(42) // bad_certificate
+		}
+
+		state.VerifiedChains = chains
+	}
+
+	state.PeerCertificates = certs
+
+	return state, nil
+}
+
+// handshakeError returns error of TLS alert sent by server, as seen by client of a real handshake.
+func handshakeError(alert uint8) error {
+	return &net.OpError{
+		Op:  "remote error",
+		Net: "tcp",
+		Err: tls.AlertError(alert),
+	}
+}
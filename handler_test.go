@@ -0,0 +1,204 @@
+package httptesting
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/dolab/httptesting/certs"
+	"github.com/golib/assert"
+)
+
+func TestNewHandler(t *testing.T) {
+	it := assert.New(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "handler", Path: "/"})
+		http.Redirect(w, r, "/profile", http.StatusSeeOther)
+	})
+	mux.HandleFunc("GET /profile", func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("session")
+		if err != nil {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"session":"` + cookie.Value + `","remote":"` + r.RemoteAddr + `","host":"` + r.Host + `"}`))
+	})
+	mux.HandleFunc("GET /panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	client := NewHandler(mux, WithRecordRequests(0))
+	defer client.Close()
+
+	it.Nil(client.server)
+	it.Equal("http://localhost/profile", client.Url("/profile"))
+
+	request := client.New(t)
+	request.Get("/profile")
+	request.AssertForbidden()
+
+	// it should follow redirects with cookies
+	request.Post("/login", "text/plain", []byte("name=httptesting"))
+	request.AssertOK()
+	request.AssertJSONPath("session", "handler")
+	request.AssertJSONPath("remote", "127.0.0.1:1234")
+	request.AssertJSONPath("host", "localhost")
+
+	request.Head("/profile")
+	request.AssertOK()
+	request.AssertEmpty()
+
+	request.AssertReceivedCount(4)
+	request.AssertReceived("POST", "/login")
+
+	received := client.ReceivedRequests()
+	if it.Len(received, 4) {
+		it.Equal([]byte("name=httptesting"), received[1].Body)
+		it.Nil(received[1].TLS)
+	}
+
+	// it should return error of handler panic
+	request = client.New(t).NonFatal()
+	request.Get("/panic")
+	if it.NotNil(request.Err()) {
+		it.Contains(request.Err().Error(), "handler panic serving GET /panic: boom")
+	}
+
+	// it should report connection reuse is unsupported
+	harness := &mockTesting{}
+
+	request = client.New(harness)
+	request.Get("/profile")
+	it.False(request.AssertConnectionReused())
+	if it.Len(harness.errors, 1) {
+		it.Contains(harness.errors[0], "dispatched in memory by client of NewHandler")
+	}
+
+	// it should reject custom transport
+	it.Panics(func() {
+		NewHandler(mux, WithTransport(http.DefaultTransport))
+	})
+}
+
+func TestNewHandlerWithTLS(t *testing.T) {
+	it := assert.New(t)
+
+	ca, err := certs.NewCA()
+	if !it.Nil(err) {
+		return
+	}
+
+	clientCert, err := ca.Issue(certs.WithCommonName("httptesting"), certs.WithExtKeyUsage(x509.ExtKeyUsageClientAuth))
+	if !it.Nil(err) {
+		return
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(r.TLS.ServerName))
+	})
+
+	client := NewHandler(handler,
+		WithTLS(),
+		WithClientAuth(tls.RequireAndVerifyClientCert, ca.Pool()),
+		WithClientCert(clientCert),
+	)
+	defer client.Close()
+
+	request := client.New(t)
+	request.Get("/tls")
+	request.AssertOK()
+	request.AssertContains("localhost")
+	request.AssertPeerCertificate("httptesting")
+
+	// it should not present client certificate without request of server
+	client = NewHandler(handler, WithTLS(), WithClientCert(clientCert))
+	defer client.Close()
+
+	request = client.New(t)
+	request.Get("/tls")
+	request.AssertOK()
+	it.Empty(client.PeerCertificates())
+}
+
+func TestNewHandlerWithUntrustedClientCert(t *testing.T) {
+	it := assert.New(t)
+
+	ca, err := certs.NewCA()
+	if !it.Nil(err) {
+		return
+	}
+
+	untrusted, err := certs.NewCA()
+	if !it.Nil(err) {
+		return
+	}
+
+	clientCert, err := untrusted.Issue(certs.WithCommonName("httptesting"), certs.WithExtKeyUsage(x509.ExtKeyUsageClientAuth))
+	if !it.Nil(err) {
+		return
+	}
+
+	var served int
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served++
+
+		w.WriteHeader(http.StatusOK)
+	})
+
+	// it should reject client certificate issued by untrusted CA
+	client := NewHandler(handler,
+		WithTLS(),
+		WithClientAuth(tls.RequireAndVerifyClientCert, ca.Pool()),
+		WithClientCert(clientCert),
+	)
+	defer client.Close()
+
+	request := client.New(t).NonFatal()
+	request.Get("/tls")
+	if it.NotNil(request.Err()) {
+		var alertErr tls.AlertError
+		if it.True(errors.As(request.Err(), &alertErr)) {
+			it.Equal("tls: bad certificate", alertErr.Error())
+		}
+	}
+
+	// it should reject request without client certificate
+	client = NewHandler(handler, WithTLS(), WithClientAuth(tls.RequireAndVerifyClientCert, ca.Pool()))
+	defer client.Close()
+
+	request = client.New(t).NonFatal()
+	request.Get("/tls")
+	if it.NotNil(request.Err()) {
+		var alertErr tls.AlertError
+		it.True(errors.As(request.Err(), &alertErr))
+	}
+
+	// it should accept client certificate without verification
+	client = NewHandler(handler,
+		WithTLS(),
+		WithClientAuth(tls.RequireAnyClientCert, ca.Pool()),
+		WithClientCert(clientCert),
+	)
+	defer client.Close()
+
+	request = client.New(t)
+	request.Get("/tls")
+	request.AssertOK()
+	request.AssertPeerCertificate("httptesting")
+
+	it.Equal(1, served)
+}
@@ -0,0 +1,429 @@
+package httptesting
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	harVersion = "1.2"
+	harCreator = "httptesting"
+)
+
+// harRecorder collects exchanges of requests into HTTP Archive (HAR) 1.2 format.
+type harRecorder struct {
+	mux      sync.Mutex
+	filename string
+	entries  []*harEntry
+	dirty    bool
+}
+
+type harLog struct {
+	Log struct {
+		Version string      `json:"version"`
+		Creator harCreated  `json:"creator"`
+		Entries []*harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+type harCreated struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	Error           string      `json:"_error,omitempty"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harCookie    `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harCookie    `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harCookie struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Path     string `json:"path,omitempty"`
+	Domain   string `json:"domain,omitempty"`
+	Expires  string `json:"expires,omitempty"`
+	HTTPOnly bool   `json:"httpOnly,omitempty"`
+	Secure   bool   `json:"secure,omitempty"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Encoding string `json:"_encoding,omitempty"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type harTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// Transport returns a http.RoundTripper which records every exchange of transport given, including each hop of redirects.
+// An exchange is recorded once its response body is read to the end or closed.
+func (recorder *harRecorder) Transport(transport http.RoundTripper) http.RoundTripper {
+	return &harTransport{
+		recorder:  recorder,
+		transport: transport,
+	}
+}
+
+type harTransport struct {
+	recorder  *harRecorder
+	transport http.RoundTripper
+}
+
+func (transport *harTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	requestBody, err := readRequestBody(r)
+	if err != nil {
+		return nil, err
+	}
+
+	trace := newRequestTrace()
+
+	resp, err := transport.transport.RoundTrip(r.WithContext(httptrace.WithClientTrace(r.Context(), trace.clientTrace())))
+	if err != nil {
+		trace.finish()
+
+		transport.recorder.record(r, requestBody, nil, nil, trace, err)
+		return resp, err
+	}
+
+	body := &harBody{
+		ReadCloser: resp.Body,
+	}
+	body.done = func(err error) {
+		trace.finish()
+
+		transport.recorder.record(r, requestBody, resp, body.buf.Bytes(), trace, err)
+	}
+	resp.Body = body
+
+	return resp, nil
+}
+
+func (transport *harTransport) CloseIdleConnections() {
+	if closer, ok := transport.transport.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+// harBody copies response body for recording, and invokes done once after reading to the end or closing.
+type harBody struct {
+	io.ReadCloser
+	buf  bytes.Buffer
+	once sync.Once
+	done func(err error)
+}
+
+func (body *harBody) Read(p []byte) (int, error) {
+	n, err := body.ReadCloser.Read(p)
+	body.buf.Write(p[:n])
+
+	switch err {
+	case nil:
+		// ignore
+
+	case io.EOF:
+		body.once.Do(func() {
+			body.done(nil)
+		})
+
+	default:
+		body.once.Do(func() {
+			body.done(err)
+		})
+	}
+
+	return n, err
+}
+
+func (body *harBody) Close() error {
+	err := body.ReadCloser.Close()
+
+	body.once.Do(func() {
+		body.done(nil)
+	})
+
+	return err
+}
+
+// record adds an exchange of request. The response is nil if the request failed with err.
+func (recorder *harRecorder) record(request *http.Request, requestBody []byte, response *http.Response, responseBody []byte, trace *requestTrace, err error) {
+	trace.mux.Lock()
+	defer trace.mux.Unlock()
+
+	total, _ := between(trace.start, trace.done)
+
+	entry := &harEntry{
+		StartedDateTime: trace.start.Format(time.RFC3339Nano),
+		Time:            harMillis(total),
+		Request: harRequest{
+			Method:      request.Method,
+			URL:         request.URL.String(),
+			HTTPVersion: request.Proto,
+			Cookies:     harCookies(request.Cookies()),
+			Headers:     harNameValues(redactHeader(request.Header)),
+			HeadersSize: -1,
+			BodySize:    len(requestBody),
+		},
+		Response: harResponse{
+			Cookies:     []harCookie{},
+			Headers:     []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Timings: trace.harTimings(),
+	}
+	if len(entry.Request.HTTPVersion) == 0 {
+		entry.Request.HTTPVersion = "HTTP/1.1"
+	}
+
+	entry.Request.QueryString = harNameValues(request.URL.Query())
+
+	if len(requestBody) > 0 {
+		text, encoding := encodeBody(requestBody)
+
+		entry.Request.PostData = &harPostData{
+			MimeType: request.Header.Get("Content-Type"),
+			Text:     text,
+			Encoding: encoding,
+		}
+	}
+
+	if response != nil {
+		entry.Response.Status = response.StatusCode
+		entry.Response.StatusText = http.StatusText(response.StatusCode)
+		entry.Response.HTTPVersion = response.Proto
+		entry.Response.Cookies = harCookies(response.Cookies())
+		entry.Response.Headers = harNameValues(redactHeader(response.Header))
+		entry.Response.RedirectURL = response.Header.Get("Location")
+		entry.Response.BodySize = len(responseBody)
+
+		text, encoding := encodeBody(responseBody)
+
+		entry.Response.Content = harContent{
+			Size:     len(responseBody),
+			MimeType: response.Header.Get("Content-Type"),
+			Text:     text,
+			Encoding: encoding,
+		}
+	}
+
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	recorder.mux.Lock()
+	defer recorder.mux.Unlock()
+
+	recorder.entries = append(recorder.entries, entry)
+	recorder.dirty = true
+}
+
+// save writes recorded entries into the HAR file. It does nothing unless new entries recorded.
+func (recorder *harRecorder) save() error {
+	recorder.mux.Lock()
+	defer recorder.mux.Unlock()
+
+	if !recorder.dirty {
+		return nil
+	}
+
+	var log harLog
+	log.Log.Version = harVersion
+	log.Log.Creator = harCreated{Name: harCreator, Version: moduleVersion()}
+	log.Log.Entries = recorder.entries
+
+	data, err := json.MarshalIndent(&log, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(recorder.filename), 0755)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(recorder.filename, append(data, '\n'), 0644)
+	if err != nil {
+		return err
+	}
+
+	recorder.dirty = false
+	return nil
+}
+
+// harTimings returns timings of HAR in milliseconds, -1 stands for not applicable.
+//
+// NOTE: The caller MUST hold lock of the trace.
+func (trace *requestTrace) harTimings() harTimings {
+	timings := harTimings{
+		Blocked: -1,
+		DNS:     -1,
+		Connect: -1,
+		SSL:     -1,
+	}
+
+	var dialing time.Duration
+	if d, ok := between(trace.dnsStart, trace.dnsDone); ok {
+		timings.DNS = harMillis(d)
+		dialing += d
+	}
+
+	connectDone := trace.connectDone
+	if trace.tlsDone.After(connectDone) {
+		connectDone = trace.tlsDone
+	}
+	if d, ok := between(trace.connectStart, connectDone); ok {
+		timings.Connect = harMillis(d)
+		dialing += d
+	}
+	if d, ok := between(trace.tlsStart, trace.tlsDone); ok {
+		timings.SSL = harMillis(d)
+	}
+
+	if d, ok := between(trace.getConn, trace.gotConn); ok {
+		timings.Blocked = harMillis(d - dialing)
+		if timings.Blocked < 0 {
+			timings.Blocked = 0
+		}
+	}
+
+	if d, ok := between(trace.gotConn, trace.wroteRequest); ok {
+		timings.Send = harMillis(d)
+	}
+	if d, ok := between(trace.wroteRequest, trace.firstByte); ok {
+		timings.Wait = harMillis(d)
+	}
+	if d, ok := between(trace.firstByte, trace.done); ok {
+		timings.Receive = harMillis(d)
+	}
+
+	return timings
+}
+
+func harMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// harNameValues returns pairs of headers or query sorted by name.
+func harNameValues(values map[string][]string) []harNameValue {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := []harNameValue{}
+	for _, name := range names {
+		for _, value := range values[name] {
+			pairs = append(pairs, harNameValue{Name: name, Value: value})
+		}
+	}
+
+	return pairs
+}
+
+// harCookies returns cookies with values redacted.
+func harCookies(cookies []*http.Cookie) []harCookie {
+	items := []harCookie{}
+	for _, cookie := range cookies {
+		item := harCookie{
+			Name:     cookie.Name,
+			Value:    debugRedacted,
+			Path:     cookie.Path,
+			Domain:   cookie.Domain,
+			HTTPOnly: cookie.HttpOnly,
+			Secure:   cookie.Secure,
+		}
+		if !cookie.Expires.IsZero() {
+			item.Expires = cookie.Expires.Format(time.RFC3339)
+		}
+
+		items = append(items, item)
+	}
+
+	return items
+}
+
+// moduleVersion returns version of the library resolved by build info, or (devel) if it is unknown,
+// e.g. running tests of the library itself.
+func moduleVersion() string {
+	path := reflect.TypeOf(harRecorder{}).PkgPath()
+
+	info, ok := debug.ReadBuildInfo()
+	if ok {
+		if info.Main.Path == path && len(info.Main.Version) > 0 {
+			return info.Main.Version
+		}
+
+		for _, module := range info.Deps {
+			if module.Path != path {
+				continue
+			}
+
+			if module.Replace != nil && len(module.Replace.Version) > 0 {
+				return module.Replace.Version
+			}
+
+			return module.Version
+		}
+	}
+
+	return "(devel)"
+}
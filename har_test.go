@@ -0,0 +1,175 @@
+package httptesting
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+func TestClient_WithHAR(t *testing.T) {
+	it := assert.New(t)
+
+	server := newMockServer("GET", "/har", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/har?name=redirected", http.StatusFound)
+			return
+		}
+
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "har"})
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"method":"` + r.Method + `"}`))
+	})
+
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	filename := filepath.Join(t.TempDir(), "artifacts", "requests.har")
+
+	client := New(ts.URL, WithHAR(filename))
+
+	request := client.New(t)
+	request.WithHeader("X-Mock-Client", "httptesting")
+	request.WithHeader("Authorization", "Bearer secret")
+	request.Get("/har?name=httptesting")
+	request.AssertOK()
+	request.PostJSON("/har", map[string]string{"name": "httptesting"})
+	request.AssertOK()
+
+	// it should record each hop of redirects
+	request.Get("/redirect")
+	request.AssertOK()
+
+	// it should record failed request
+	failed := New("127.0.0.1:1", WithHAR(filename))
+	failed.har = client.har
+
+	request = failed.New(t).NonFatal()
+	request.Get("/unreachable")
+	it.NotNil(request.Err())
+
+	it.Nil(client.Close())
+
+	data, err := os.ReadFile(filename)
+	if !it.Nil(err) {
+		return
+	}
+
+	var har struct {
+		Log struct {
+			Version string
+			Creator struct {
+				Name    string
+				Version string
+			}
+			Entries []struct {
+				StartedDateTime string
+				Time            float64
+				Request         struct {
+					Method      string
+					URL         string
+					HTTPVersion string
+					Headers     []harNameValue
+					QueryString []harNameValue
+					PostData    *harPostData
+					BodySize    int
+				}
+				Response struct {
+					Status     int
+					StatusText string
+					Cookies    []harCookie
+					Content    harContent
+				}
+				Timings harTimings
+				Error   string `json:"_error"`
+			}
+		}
+	}
+
+	err = json.Unmarshal(data, &har)
+	if !it.Nil(err) {
+		return
+	}
+
+	it.Equal("1.2", har.Log.Version)
+	it.Equal("httptesting", har.Log.Creator.Name)
+	it.Equal(moduleVersion(), har.Log.Creator.Version)
+	it.NotEqual("1.2", har.Log.Creator.Version)
+	if !it.Len(har.Log.Entries, 5) {
+		return
+	}
+
+	entry := har.Log.Entries[0]
+	it.NotEmpty(entry.StartedDateTime)
+	it.True(entry.Time > 0)
+	it.Equal("GET", entry.Request.Method)
+	it.Equal(ts.URL+"/har?name=httptesting", entry.Request.URL)
+	it.Equal("HTTP/1.1", entry.Request.HTTPVersion)
+	it.Contains(entry.Request.Headers, harNameValue{Name: "X-Mock-Client", Value: "httptesting"})
+	it.Contains(entry.Request.Headers, harNameValue{Name: "Authorization", Value: "[REDACTED]"})
+	it.NotContains(string(data), "secret")
+	it.Equal([]harNameValue{{Name: "name", Value: "httptesting"}}, entry.Request.QueryString)
+	it.Nil(entry.Request.PostData)
+	it.Equal(http.StatusOK, entry.Response.Status)
+	it.Equal("OK", entry.Response.StatusText)
+	it.Equal([]harCookie{{Name: "session", Value: "[REDACTED]"}}, entry.Response.Cookies)
+	it.Equal(harContent{Size: 16, MimeType: "application/json", Text: `{"method":"GET"}`}, entry.Response.Content)
+	it.True(entry.Timings.Connect >= 0)
+	it.Equal(float64(-1), entry.Timings.SSL)
+	it.True(entry.Timings.Wait >= 0)
+
+	entry = har.Log.Entries[1]
+	it.Equal("POST", entry.Request.Method)
+	if it.NotNil(entry.Request.PostData) {
+		it.Equal("application/json", entry.Request.PostData.MimeType)
+		it.Equal(`{"name":"httptesting"}`, entry.Request.PostData.Text)
+	}
+	it.Equal(22, entry.Request.BodySize)
+	it.Equal(`{"method":"POST"}`, entry.Response.Content.Text)
+
+	entry = har.Log.Entries[2]
+	it.Equal(ts.URL+"/redirect", entry.Request.URL)
+	it.Equal(http.StatusFound, entry.Response.Status)
+
+	entry = har.Log.Entries[3]
+	it.Equal(ts.URL+"/har?name=redirected", entry.Request.URL)
+	it.Equal(http.StatusOK, entry.Response.Status)
+	it.Contains(entry.Request.Headers, harNameValue{Name: "Cookie", Value: "[REDACTED]"})
+
+	entry = har.Log.Entries[4]
+	it.Equal(0, entry.Response.Status)
+	it.Contains(entry.Error, "127.0.0.1:1")
+}
+
+func TestClient_CloseWithHARError(t *testing.T) {
+	it := assert.New(t)
+
+	server := newMockServer("GET", "/har", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	// parent of HAR file is a regular file
+	parent := filepath.Join(t.TempDir(), "file")
+	it.Nil(os.WriteFile(parent, nil, 0644))
+
+	client := New(ts.URL, WithHAR(filepath.Join(parent, "requests.har")))
+
+	request := client.New(t)
+	request.Get("/har")
+	request.AssertOK()
+
+	// it should return error of saving HAR file instead of panic
+	err := client.Close()
+	if it.NotNil(err) {
+		it.Contains(err.Error(), "httptesting: Close: HAR:")
+	}
+}
@@ -0,0 +1,111 @@
+package httptesting
+
+import (
+	"bytes"
+
+	"github.com/golib/assert"
+	"golang.org/x/net/html"
+)
+
+// AssertSelectorExists asserts that the response body of HTML contains element matching the CSS selector.
+func (r *Request) AssertSelectorExists(selector string) bool {
+	_, ok := r.firstHTML(selector)
+
+	return ok
+}
+
+// AssertSelectorCount asserts that the response body of HTML contains n elements matching the CSS selector.
+func (r *Request) AssertSelectorCount(selector string, n int) bool {
+	nodes, ok := r.selectHTML(selector)
+	if !ok {
+		return false
+	}
+
+	return assert.EqualValues(r.reporter(), n, len(nodes),
+		"Expected response body contains %d HTML element(s) matching selector %q, but got %d",
+		n,
+		selector,
+		len(nodes),
+	)
+}
+
+// AssertSelectorText asserts that text of the first element matching the CSS selector is equal to text,
+// whitespace of the text is collapsed before comparing.
+func (r *Request) AssertSelectorText(selector, text string) bool {
+	node, ok := r.firstHTML(selector)
+	if !ok {
+		return false
+	}
+
+	actual := htmlText(node)
+
+	return assert.EqualValues(r.reporter(), text, actual,
+		"Expected text of HTML element matching selector %q of %q, but got %q",
+		selector,
+		text,
+		actual,
+	)
+}
+
+// AssertSelectorAttr asserts that the first element matching the CSS selector has attribute with value.
+func (r *Request) AssertSelectorAttr(selector, name, value string) bool {
+	node, ok := r.firstHTML(selector)
+	if !ok {
+		return false
+	}
+
+	actual, ok := htmlAttr(node, name)
+	if !ok {
+		return assert.Fail(r.reporter(), "Response body: HTML attribute "+name+" of "+selector+" (*required)",
+			"Expected HTML element matching selector %q has attribute %s, but got none",
+			selector,
+			name,
+		)
+	}
+
+	return assert.EqualValues(r.reporter(), value, actual,
+		"Expected attribute %s of HTML element matching selector %q of %q, but got %q",
+		name,
+		selector,
+		value,
+		actual,
+	)
+}
+
+// firstHTML returns the first element matching the CSS selector within the response body of HTML.
+func (r *Request) firstHTML(selector string) (*html.Node, bool) {
+	nodes, ok := r.selectHTML(selector)
+	if !ok {
+		return nil, false
+	}
+
+	if len(nodes) == 0 {
+		return nil, assert.Fail(r.reporter(), "Response body: HTML element of "+selector+" (*required)",
+			"Expected response body contains HTML element matching selector %q, but got none",
+			selector,
+		)
+	}
+
+	return nodes[0], true
+}
+
+// selectHTML returns elements matching the CSS selector within the response body of HTML.
+func (r *Request) selectHTML(selector string) ([]*html.Node, bool) {
+	compiled, err := parseSelector(selector)
+	if err != nil {
+		return nil, assert.Fail(r.reporter(), "CSS selector: "+selector+" (*invalid)",
+			"Expected valid CSS selector, but got %v",
+			err,
+		)
+	}
+
+	doc, err := html.Parse(bytes.NewReader(r.ResponseBody))
+	if err != nil {
+		return nil, assert.Fail(r.reporter(), "Response body: "+excerpt(r.ResponseBody, 0),
+			"Expected response body of HTML, but got %v",
+			err,
+		)
+	}
+
+	return compiled.selectAll(doc), true
+}
@@ -0,0 +1,71 @@
+package httptesting
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+func TestRequest_AssertSelector(t *testing.T) {
+	it := assert.New(t)
+
+	server := newMockServer("GET", "/html", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`<!DOCTYPE html>
+<html>
+<head><title>  Sign in
+	- httptesting </title></head>
+<body>
+	<ul class="nav">
+		<li><a href="/">Home</a></li>
+		<li><a href="https://github.com/dolab/httptesting" rel="external">GitHub</a></li>
+	</ul>
+	<form id="login" action="/session" method="post">
+		<input type="text" name="username">
+		<input type="password" name="password">
+		<button type="submit" class="btn btn-primary">Sign <b>in</b></button>
+	</form>
+</body>
+</html>`))
+	})
+
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	request := New(ts.URL).New(t)
+	request.Get("/html")
+	request.AssertOK()
+	request.AssertSelectorExists("form#login")
+	request.AssertSelectorExists("a[rel=external]")
+	request.AssertSelectorCount("form input", 2)
+	request.AssertSelectorCount("ul.nav > li > a", 2)
+	request.AssertSelectorCount("a[href^=https], input[type=password]", 2)
+	request.AssertSelectorCount("table", 0)
+	request.AssertSelectorText("title", "Sign in - httptesting")
+	request.AssertSelectorText(".btn.btn-primary", "Sign in")
+	request.AssertSelectorAttr("form", "action", "/session")
+	request.AssertSelectorAttr("#login > [type='text']", "name", "username")
+
+	// it should report failures
+	harness := &mockTesting{}
+
+	request = New(ts.URL).New(harness)
+	request.Get("/html")
+	it.False(request.AssertSelectorExists("form#signup"))
+	it.False(request.AssertSelectorCount("li", 3))
+	it.False(request.AssertSelectorText("title", "Sign up"))
+	it.False(request.AssertSelectorAttr("form", "method", "get"))
+	it.False(request.AssertSelectorAttr("form", "enctype", ""))
+	it.False(request.AssertSelectorExists("form["))
+	if it.Len(harness.errors, 6) {
+		it.Contains(harness.errors[0], `matching selector "form#signup", but got none`)
+		it.Contains(harness.errors[1], `contains 3 HTML element(s) matching selector "li", but got 2`)
+		it.Contains(harness.errors[2], `of "Sign up", but got "Sign in - httptesting"`)
+		it.Contains(harness.errors[3], `of "get", but got "post"`)
+		it.Contains(harness.errors[4], "has attribute enctype, but got none")
+		it.Contains(harness.errors[5], "Expected valid CSS selector")
+	}
+}
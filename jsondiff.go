@@ -0,0 +1,207 @@
+package httptesting
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golib/assert"
+)
+
+const (
+	colorReset  = "\x1b[0m"
+	colorRed    = "\x1b[31m"
+	colorGreen  = "\x1b[32m"
+	colorYellow = "\x1b[33m"
+)
+
+// AssertJSONEqual asserts that the response body is semantically equal to expected JSON, regardless
+// of key ordering and whitespace. Values at ignored paths are not compared, see AssertJSONPath for
+// syntax of path, and both "*" and "#" match any key or index.
+//
+// The expected can be a string or []byte of JSON, or any other Go value encoded by json.Marshal.
+// NOTE: Diff is colored only with WithColor option, and NO_COLOR environment disables it anyway.
+func (r *Request) AssertJSONEqual(expected interface{}, ignores ...string) bool {
+	var (
+		value interface{}
+		err   error
+	)
+	switch typo := expected.(type) {
+	case string:
+		value, err = decodeJSON([]byte(typo))
+
+	case []byte:
+		value, err = decodeJSON(typo)
+
+	default:
+		value, err = normalizeJSON(expected)
+	}
+	if err != nil {
+		return assert.Fail(r.reporter(), "Expected JSON: "+fmt.Sprintf("%T", expected)+" (*invalid)",
+			"Expected JSON decoded, but got %v",
+			err,
+		)
+	}
+
+	actual, err := decodeJSON(r.ResponseBody)
+	if err != nil {
+		return assert.Fail(r.reporter(), "Response body: "+excerpt(r.ResponseBody, 0),
+			"Expected response body of JSON, but got %v",
+			err,
+		)
+	}
+
+	differ := &jsonDiffer{
+		color: r.Client.color && len(os.Getenv("NO_COLOR")) == 0,
+	}
+	for _, ignore := range ignores {
+		differ.ignores = append(differ.ignores, splitJSONPath(ignore))
+	}
+
+	differ.diff(nil, value, actual)
+	if len(differ.diffs) == 0 {
+		return true
+	}
+
+	return assert.Fail(r.reporter(), "Response body: JSON (*mismatched)",
+		"Expected response body equal to JSON, but got %d difference(s) (-expected +actual):\n%s",
+		len(differ.diffs),
+		strings.Join(differ.diffs, "\n"),
+	)
+}
+
+type jsonDiffer struct {
+	ignores [][]string
+	color   bool
+	diffs   []string
+}
+
+func (differ *jsonDiffer) diff(keys []string, expected, actual interface{}) {
+	if differ.ignored(keys) {
+		return
+	}
+
+	switch typo := expected.(type) {
+	case map[string]interface{}:
+		object, ok := actual.(map[string]interface{})
+		if !ok {
+			break
+		}
+
+		names := make([]string, 0, len(typo)+len(object))
+		for name := range typo {
+			names = append(names, name)
+		}
+		for name := range object {
+			if _, ok := typo[name]; !ok {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			path := append(keys[:len(keys):len(keys)], name)
+
+			expectedValue, expectedOK := typo[name]
+			actualValue, actualOK := object[name]
+			switch {
+			case expectedOK && actualOK:
+				differ.diff(path, expectedValue, actualValue)
+
+			case expectedOK:
+				differ.missing(path, expectedValue)
+
+			default:
+				differ.unexpected(path, actualValue)
+			}
+		}
+
+		return
+
+	case []interface{}:
+		array, ok := actual.([]interface{})
+		if !ok {
+			break
+		}
+
+		for i := 0; i < len(typo) || i < len(array); i++ {
+			path := append(keys[:len(keys):len(keys)], strconv.Itoa(i))
+
+			switch {
+			case i < len(typo) && i < len(array):
+				differ.diff(path, typo[i], array[i])
+
+			case i < len(typo):
+				differ.missing(path, typo[i])
+
+			default:
+				differ.unexpected(path, array[i])
+			}
+		}
+
+		return
+	}
+
+	if !equalJSON(expected, actual) {
+		differ.add(colorYellow, "~", keys, jsonString(expected)+" => "+jsonString(actual))
+	}
+}
+
+func (differ *jsonDiffer) missing(keys []string, value interface{}) {
+	if differ.ignored(keys) {
+		return
+	}
+
+	differ.add(colorRed, "-", keys, jsonString(value))
+}
+
+func (differ *jsonDiffer) unexpected(keys []string, value interface{}) {
+	if differ.ignored(keys) {
+		return
+	}
+
+	differ.add(colorGreen, "+", keys, jsonString(value))
+}
+
+func (differ *jsonDiffer) add(color, sign string, keys []string, value string) {
+	path := "(root)"
+	if len(keys) > 0 {
+		escaped := make([]string, len(keys))
+		for i, key := range keys {
+			escaped[i] = strings.ReplaceAll(key, ".", `\.`)
+		}
+
+		path = strings.Join(escaped, ".")
+	}
+
+	line := fmt.Sprintf("\t%s %s: %s", sign, path, value)
+	if differ.color {
+		line = color + line + colorReset
+	}
+
+	differ.diffs = append(differ.diffs, line)
+}
+
+func (differ *jsonDiffer) ignored(keys []string) bool {
+	for _, ignore := range differ.ignores {
+		if len(ignore) != len(keys) {
+			continue
+		}
+
+		matched := true
+		for i, key := range ignore {
+			if key != keys[i] && key != "*" && key != "#" {
+				matched = false
+				break
+			}
+		}
+
+		if matched {
+			return true
+		}
+	}
+
+	return false
+}
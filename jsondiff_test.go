@@ -0,0 +1,99 @@
+package httptesting
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+func TestRequest_AssertJSONEqual(t *testing.T) {
+	it := assert.New(t)
+
+	server := newMockServer("GET", "/jsondiff", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{
+			"id": "5f8d0d55",
+			"user": {"name": "httptesting", "age": 3},
+			"addresses": [{"name": "china", "created_at": 1600000000}, {"name": "USA", "created_at": 1600000001}]
+		}`))
+	})
+
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	request := New(ts.URL).New(t)
+	request.GetJSON("/jsondiff")
+	request.AssertOK()
+
+	// it should ignore key ordering and whitespace
+	request.AssertJSONEqual(`{"user":{"age":3,"name":"httptesting"},"id":"5f8d0d55","addresses":[{"created_at":1600000000,"name":"china"},{"created_at":1600000001,"name":"USA"}]}`)
+
+	// it should work with Go value and ignored paths
+	request.AssertJSONEqual(map[string]interface{}{
+		"id":   "unknown",
+		"user": map[string]interface{}{"name": "httptesting", "age": 3},
+		"addresses": []map[string]interface{}{
+			{"name": "china"},
+			{"name": "USA"},
+		},
+	}, "id", "addresses.#.created_at")
+
+	// it should report structural diff without colors by default
+	harness := &mockTesting{}
+
+	request = New(ts.URL).New(harness)
+	request.GetJSON("/jsondiff")
+	it.False(request.AssertJSONEqual(`{
+		"user": {"name": "unknown", "age": 3, "email": "httptesting@example.com"},
+		"addresses": [{"name": "china"}]
+	}`, "addresses.*.created_at"))
+	if it.Len(harness.errors, 1) {
+		it.Contains(harness.errors[0], "4 difference(s)")
+		it.Contains(harness.errors[0], `+ addresses.1: {"created_at":1600000001,"name":"USA"}`)
+		it.Contains(harness.errors[0], `+ id: "5f8d0d55"`)
+		it.Contains(harness.errors[0], `- user.email: "httptesting@example.com"`)
+		it.Contains(harness.errors[0], `~ user.name: "unknown" => "httptesting"`)
+		it.NotContains(harness.errors[0], colorReset)
+	}
+
+	// it should color diff with WithColor option
+	harness = &mockTesting{}
+
+	request = New(ts.URL, WithColor()).New(harness)
+	request.GetJSON("/jsondiff")
+	it.False(request.AssertJSONEqual(`{"id": "unknown"}`, "user", "addresses"))
+	if it.Len(harness.errors, 1) {
+		it.Contains(harness.errors[0], colorYellow+`	~ id: "unknown" => "5f8d0d55"`+colorReset)
+	}
+
+	// it should not color diff with NO_COLOR environment
+	t.Setenv("NO_COLOR", "1")
+
+	harness = &mockTesting{}
+
+	request = New(ts.URL, WithColor()).New(harness)
+	request.GetJSON("/jsondiff")
+	it.False(request.AssertJSONEqual(`{"id": "unknown"}`, "user", "addresses"))
+	if it.Len(harness.errors, 1) {
+		it.NotContains(harness.errors[0], colorReset)
+	}
+}
+
+func Test_jsonDiffer(t *testing.T) {
+	it := assert.New(t)
+
+	differ := &jsonDiffer{color: true}
+	differ.diff(nil, []interface{}{1.0}, map[string]interface{}{"a.b": 1.0})
+	if it.Len(differ.diffs, 1) {
+		it.Equal(colorYellow+"\t~ (root): [1] => {\"a.b\":1}"+colorReset, differ.diffs[0])
+	}
+
+	differ = &jsonDiffer{}
+	differ.diff(nil, map[string]interface{}{"a.b": 1.0}, map[string]interface{}{"a.b": 2.0})
+	if it.Len(differ.diffs, 1) {
+		it.Equal("\t~ a\\.b: 1 => 2", differ.diffs[0])
+	}
+}
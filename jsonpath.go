@@ -0,0 +1,380 @@
+package httptesting
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golib/assert"
+)
+
+// AssertJSONPath asserts that the response body contains JSON value at the path.
+//
+// The path is a dot separated keys in gjson style, e.g.
+//
+//   - "user.name" for value of a key
+//   - "addresses.1.name" for value of an array element
+//   - "addresses.#" for length of an array
+//   - "addresses.#.name" or "addresses.*.name" for values of all array elements
+//   - "user.*" for values of all object members, ordered by key
+//   - "user\.name" for key containing dot
+func (r *Request) AssertJSONPath(path string, expected interface{}) bool {
+	actual, ok := r.lookupJSONPath(path)
+	if !ok {
+		return false
+	}
+
+	value, err := normalizeJSON(expected)
+	if err != nil {
+		return assert.Fail(r.reporter(), "Expected JSON value: "+fmt.Sprintf("%T", expected)+" (*invalid)",
+			"Expected JSON value at path %q encoded, but got %v",
+			path,
+			err,
+		)
+	}
+
+	if !equalJSON(value, actual) {
+		return assert.Fail(r.reporter(), "Response body: JSON value at "+path+" (*mismatched)",
+			"Expected JSON value at path %q of %s, but got %s",
+			path,
+			jsonString(value),
+			jsonString(actual),
+		)
+	}
+
+	return true
+}
+
+// AssertJSONPathExists asserts that the response body contains JSON path.
+func (r *Request) AssertJSONPathExists(path string) bool {
+	_, ok := r.lookupJSONPath(path)
+
+	return ok
+}
+
+// AssertJSONPathLen asserts that the JSON array, object or string at the path has length of n.
+func (r *Request) AssertJSONPathLen(path string, n int) bool {
+	actual, ok := r.lookupJSONPath(path)
+	if !ok {
+		return false
+	}
+
+	length := -1
+	switch typo := actual.(type) {
+	case []interface{}:
+		length = len(typo)
+
+	case map[string]interface{}:
+		length = len(typo)
+
+	case string:
+		length = len([]rune(typo))
+	}
+
+	if length != n {
+		return assert.Fail(r.reporter(), "Response body: JSON length at "+path+" (*mismatched)",
+			"Expected JSON value at path %q has length of %d, but got %s",
+			path,
+			n,
+			jsonString(actual),
+		)
+	}
+
+	return true
+}
+
+// AssertJSONPathType asserts that the JSON value at the path is of type given, which is one of
+// "null", "boolean", "number", "string", "array" and "object".
+func (r *Request) AssertJSONPathType(path, typ string) bool {
+	actual, ok := r.lookupJSONPath(path)
+	if !ok {
+		return false
+	}
+
+	if actualType := jsonType(actual); actualType != typ {
+		return assert.Fail(r.reporter(), "Response body: JSON type at "+path+" (*mismatched)",
+			"Expected JSON value at path %q of %s type, but got %s of %s type",
+			path,
+			typ,
+			jsonString(actual),
+			actualType,
+		)
+	}
+
+	return true
+}
+
+// AssertJSONPathMatches asserts that the JSON value at the path matches the regular expression.
+// NOTE: Values other than string are matched with their JSON encoding.
+func (r *Request) AssertJSONPathMatches(path, re string) bool {
+	actual, ok := r.lookupJSONPath(path)
+	if !ok {
+		return false
+	}
+
+	pattern, err := regexp.Compile(re)
+	if err != nil {
+		return assert.Fail(r.reporter(), "Expected regexp: "+re+" (*invalid)",
+			"Expected regexp %q compiled, but got %v",
+			re,
+			err,
+		)
+	}
+
+	s, isString := actual.(string)
+	if !isString {
+		s = jsonString(actual)
+	}
+
+	if !pattern.MatchString(s) {
+		return assert.Fail(r.reporter(), "Response body: JSON value at "+path+" (*mismatched)",
+			"Expected JSON value at path %q matches regexp %q, but got %s",
+			path,
+			re,
+			jsonString(actual),
+		)
+	}
+
+	return true
+}
+
+// lookupJSONPath returns JSON value at the path of the response body, it fails the test if absent.
+func (r *Request) lookupJSONPath(path string) (interface{}, bool) {
+	data, err := decodeJSON(r.ResponseBody)
+	if err != nil {
+		return nil, assert.Fail(r.reporter(), "Response body: "+excerpt(r.ResponseBody, 0),
+			"Expected response body of JSON, but got %v",
+			err,
+		)
+	}
+
+	value, ok := lookupJSON(data, splitJSONPath(path))
+	if !ok {
+		return nil, assert.Fail(r.reporter(), "Response body: JSON path "+path+" (*required)",
+			"Expected response body contains JSON path %q, but got none",
+			path,
+		)
+	}
+
+	return value, true
+}
+
+// splitJSONPath splits path into keys by dot, dot escaped with backslash is kept.
+func splitJSONPath(path string) []string {
+	if len(path) == 0 {
+		return nil
+	}
+
+	var (
+		keys []string
+		key  strings.Builder
+	)
+	for i := 0; i < len(path); i++ {
+		switch path[i] {
+		case '\\':
+			if i+1 < len(path) {
+				i++
+			}
+
+			key.WriteByte(path[i])
+
+		case '.':
+			keys = append(keys, key.String())
+			key.Reset()
+
+		default:
+			key.WriteByte(path[i])
+		}
+	}
+
+	return append(keys, key.String())
+}
+
+// lookupJSON returns value of keys within data decoded by decodeJSON.
+func lookupJSON(data interface{}, keys []string) (interface{}, bool) {
+	if len(keys) == 0 {
+		return data, true
+	}
+
+	key, rest := keys[0], keys[1:]
+
+	switch typo := data.(type) {
+	case map[string]interface{}:
+		if key == "*" {
+			names := make([]string, 0, len(typo))
+			for name := range typo {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			values := []interface{}{}
+			for _, name := range names {
+				if value, ok := lookupJSON(typo[name], rest); ok {
+					values = append(values, value)
+				}
+			}
+
+			return values, true
+		}
+
+		value, ok := typo[key]
+		if !ok {
+			return nil, false
+		}
+
+		return lookupJSON(value, rest)
+
+	case []interface{}:
+		switch key {
+		case "#", "*":
+			if key == "#" && len(rest) == 0 {
+				return json.Number(strconv.Itoa(len(typo))), true
+			}
+
+			values := []interface{}{}
+			for _, item := range typo {
+				if value, ok := lookupJSON(item, rest); ok {
+					values = append(values, value)
+				}
+			}
+
+			return values, true
+		}
+
+		index, err := strconv.Atoi(key)
+		if err != nil || index < 0 || index >= len(typo) {
+			return nil, false
+		}
+
+		return lookupJSON(typo[index], rest)
+	}
+
+	return nil, false
+}
+
+// normalizeJSON converts v into value decoded by decodeJSON for comparison.
+func normalizeJSON(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeJSON(b)
+}
+
+// decodeJSON decodes data with numbers kept in json.Number, which avoids losing precision of
+// large integers in float64.
+func decodeJSON(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+
+	err := decoder.Decode(&value)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, errors.New("invalid character after top-level value")
+	}
+
+	return value, nil
+}
+
+// equalJSON reports whether values decoded by decodeJSON are equal, numbers are compared by value,
+// e.g. 1 is equal to 1.0 and 1e0.
+func equalJSON(expected, actual interface{}) bool {
+	switch typo := expected.(type) {
+	case json.Number:
+		number, ok := actual.(json.Number)
+
+		return ok && equalJSONNumber(typo, number)
+
+	case []interface{}:
+		array, ok := actual.([]interface{})
+		if !ok || len(array) != len(typo) {
+			return false
+		}
+
+		for i := range typo {
+			if !equalJSON(typo[i], array[i]) {
+				return false
+			}
+		}
+
+		return true
+
+	case map[string]interface{}:
+		object, ok := actual.(map[string]interface{})
+		if !ok || len(object) != len(typo) {
+			return false
+		}
+
+		for name, value := range typo {
+			other, ok := object[name]
+			if !ok || !equalJSON(value, other) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return reflect.DeepEqual(expected, actual)
+}
+
+func equalJSONNumber(x, y json.Number) bool {
+	a, ok := new(big.Rat).SetString(x.String())
+	if !ok {
+		return x == y
+	}
+
+	b, ok := new(big.Rat).SetString(y.String())
+	if !ok {
+		return false
+	}
+
+	return a.Cmp(b) == 0
+}
+
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+
+	case bool:
+		return "boolean"
+
+	case json.Number:
+		return "number"
+
+	case string:
+		return "string"
+
+	case []interface{}:
+		return "array"
+
+	case map[string]interface{}:
+		return "object"
+	}
+
+	return fmt.Sprintf("%T", v)
+}
+
+func jsonString(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+
+	return string(b)
+}
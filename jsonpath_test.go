@@ -0,0 +1,124 @@
+package httptesting
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+func TestRequest_AssertJSONPath(t *testing.T) {
+	it := assert.New(t)
+
+	server := newMockServer("GET", "/jsonpath", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"user":{"name":"httptesting","age":3,"tags":null},"addresses":[{"name":"china","zip":"100000"},{"name":"USA"}],"a.b":true}`))
+	})
+
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	request := New(ts.URL).New(t)
+	request.GetJSON("/jsonpath")
+	request.AssertOK()
+
+	request.AssertJSONPath("user.name", "httptesting")
+	request.AssertJSONPath("user.age", 3)
+	request.AssertJSONPath("user", map[string]interface{}{"name": "httptesting", "age": 3, "tags": nil})
+	request.AssertJSONPath("addresses.1.name", "USA")
+	request.AssertJSONPath("addresses.#", 2)
+	request.AssertJSONPath("addresses.#.name", []string{"china", "USA"})
+	request.AssertJSONPath("addresses.*.zip", []string{"100000"})
+	request.AssertJSONPath(`a\.b`, true)
+
+	request.AssertJSONPathExists("user.tags")
+	request.AssertJSONPathLen("addresses", 2)
+	request.AssertJSONPathLen("user", 3)
+	request.AssertJSONPathLen("user.name", 11)
+
+	request.AssertJSONPathType("user", "object")
+	request.AssertJSONPathType("user.name", "string")
+	request.AssertJSONPathType("user.age", "number")
+	request.AssertJSONPathType("user.tags", "null")
+	request.AssertJSONPathType("addresses", "array")
+	request.AssertJSONPathType(`a\.b`, "boolean")
+
+	request.AssertJSONPathMatches("user.name", `^http\w+$`)
+	request.AssertJSONPathMatches("user.age", `^\d+$`)
+
+	// it should report actual value
+	harness := &mockTesting{}
+
+	request = New(ts.URL).New(harness)
+	request.GetJSON("/jsonpath")
+
+	it.False(request.AssertJSONPath("user.name", "unknown"))
+	it.False(request.AssertJSONPathExists("addresses.2.name"))
+	it.False(request.AssertJSONPathLen("addresses", 3))
+	it.False(request.AssertJSONPathType("user.age", "string"))
+	it.False(request.AssertJSONPathMatches("addresses.0.name", "^USA$"))
+	if it.Len(harness.errors, 5) {
+		it.Contains(harness.errors[0], `"httptesting"`)
+		it.Contains(harness.errors[1], "addresses.2.name")
+		it.Contains(harness.errors[2], `[{"name":"china","zip":"100000"},{"name":"USA"}]`)
+		it.Contains(harness.errors[3], "3 of number type")
+		it.Contains(harness.errors[4], `"china"`)
+	}
+
+	// it should report invalid regexp
+	harness = &mockTesting{}
+
+	request = New(ts.URL).New(harness)
+	request.GetJSON("/jsonpath")
+
+	it.False(request.AssertJSONPathMatches("user.name", "(http"))
+	if it.Len(harness.errors, 1) {
+		it.Contains(harness.errors[0], "Expected regexp \"(http\" compiled, but got error parsing regexp")
+	}
+}
+
+func TestRequest_AssertJSONPathWithLargeNumber(t *testing.T) {
+	it := assert.New(t)
+
+	server := newMockServer("GET", "/jsonpath", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"id":9007199254740993,"ids":[18446744073709551615],"price":1.50}`))
+	})
+
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	request := New(ts.URL).New(t)
+	request.GetJSON("/jsonpath")
+	request.AssertOK()
+
+	// it should compare numbers without losing precision
+	request.AssertJSONPath("id", int64(9007199254740993))
+	request.AssertJSONPath("ids", []uint64{18446744073709551615})
+	request.AssertJSONPath("price", 1.5)
+	request.AssertJSONPath("ids.#", 1)
+	request.AssertJSONPathType("id", "number")
+
+	harness := &mockTesting{}
+
+	request = New(ts.URL).New(harness)
+	request.GetJSON("/jsonpath")
+
+	it.False(request.AssertJSONPath("id", int64(9007199254740992)))
+	if it.Len(harness.errors, 1) {
+		it.Contains(harness.errors[0], "of 9007199254740992, but got 9007199254740993")
+	}
+}
+
+func Test_splitJSONPath(t *testing.T) {
+	it := assert.New(t)
+
+	it.Empty(splitJSONPath(""))
+	it.Equal([]string{"user"}, splitJSONPath("user"))
+	it.Equal([]string{"user", "name"}, splitJSONPath("user.name"))
+	it.Equal([]string{"addresses", "#", "name"}, splitJSONPath("addresses.#.name"))
+	it.Equal([]string{"a.b", "c"}, splitJSONPath(`a\.b.c`))
+}
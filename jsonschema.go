@@ -0,0 +1,137 @@
+package httptesting
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/golib/assert"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+const (
+	jsonSchemaResource = "httptesting-schema.json"
+)
+
+var (
+	jsonSchemaPrinter  = message.NewPrinter(language.English)
+	jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+)
+
+// AssertJSONSchema asserts that the response body is valid against the JSON schema, and reports
+// every violation with its JSON pointer. Both draft 2020-12 and draft-07 are supported by $schema
+// keyword, draft 2020-12 is used if absent.
+//
+// The schema can be
+//
+//   - a string or []byte of JSON schema, e.g. `{"type": "object"}`
+//   - a string of file path, e.g. "testdata/user.schema.json"
+//   - an io.Reader of JSON schema
+//   - any other Go value, which is encoded by json.Marshal
+func (r *Request) AssertJSONSchema(schema interface{}) bool {
+	compiled, err := compileJSONSchema(schema)
+	if err != nil {
+		return assert.Fail(r.reporter(), "JSON schema: "+fmt.Sprintf("%T", schema)+" (*invalid)",
+			"Expected JSON schema compiled, but got %v",
+			err,
+		)
+	}
+
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(r.ResponseBody))
+	if err != nil {
+		return assert.Fail(r.reporter(), "Response body: "+excerpt(r.ResponseBody, 0),
+			"Expected response body of JSON, but got %v",
+			err,
+		)
+	}
+
+	err = compiled.Validate(instance)
+	if err == nil {
+		return true
+	}
+
+	validationErr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return assert.Fail(r.reporter(), "Response body: "+excerpt(r.ResponseBody, 0),
+			"Expected response body valid against JSON schema, but got %v",
+			err,
+		)
+	}
+
+	violations := jsonSchemaViolations(validationErr)
+
+	return assert.Fail(r.reporter(), "Response body: JSON schema (*mismatched)",
+		"Expected response body valid against JSON schema, but got %d violation(s):\n%s",
+		len(violations),
+		strings.Join(violations, "\n"),
+	)
+}
+
+func compileJSONSchema(schema interface{}) (*jsonschema.Schema, error) {
+	compiler := jsonschema.NewCompiler()
+	compiler.AssertFormat()
+
+	var reader io.Reader
+	switch typo := schema.(type) {
+	case string:
+		trimmed := strings.TrimSpace(typo)
+		if !strings.HasPrefix(trimmed, "{") && trimmed != "true" && trimmed != "false" {
+			return compiler.Compile(typo)
+		}
+
+		reader = strings.NewReader(typo)
+
+	case []byte:
+		reader = bytes.NewReader(typo)
+
+	case io.Reader:
+		reader = typo
+
+	default:
+		b, err := json.Marshal(schema)
+		if err != nil {
+			return nil, err
+		}
+
+		reader = bytes.NewReader(b)
+	}
+
+	doc, err := jsonschema.UnmarshalJSON(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	err = compiler.AddResource(jsonSchemaResource, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return compiler.Compile(jsonSchemaResource)
+}
+
+// jsonSchemaViolations returns leaf errors of validation in format of "<json pointer>: <error>".
+func jsonSchemaViolations(err *jsonschema.ValidationError) []string {
+	if len(err.Causes) == 0 {
+		var location strings.Builder
+		for _, token := range err.InstanceLocation {
+			location.WriteByte('/')
+			location.WriteString(jsonPointerEscaper.Replace(token))
+		}
+		if location.Len() == 0 {
+			location.WriteByte('/')
+		}
+
+		return []string{fmt.Sprintf("\t%s: %s", location.String(), err.ErrorKind.LocalizedString(jsonSchemaPrinter))}
+	}
+
+	var violations []string
+	for _, cause := range err.Causes {
+		violations = append(violations, jsonSchemaViolations(cause)...)
+	}
+
+	return violations
+}
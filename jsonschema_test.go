@@ -0,0 +1,115 @@
+package httptesting
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+func TestRequest_AssertJSONSchema(t *testing.T) {
+	it := assert.New(t)
+
+	server := newMockServer("GET", "/jsonschema", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"user":{"name":"httptesting","age":3,"email":"httptesting@example.com"},"addresses":[{"name":"china"},{"name":"USA"}]}`))
+	})
+
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	schema := `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"required": ["user", "addresses"],
+		"properties": {
+			"user": {
+				"type": "object",
+				"required": ["name", "age"],
+				"properties": {
+					"name": {"type": "string"},
+					"age": {"type": "integer", "minimum": 0},
+					"email": {"type": "string", "format": "email"}
+				}
+			},
+			"addresses": {
+				"type": "array",
+				"items": {"$ref": "#/$defs/address"}
+			}
+		},
+		"$defs": {
+			"address": {
+				"type": "object",
+				"required": ["name"]
+			}
+		}
+	}`
+
+	request := New(ts.URL).New(t)
+	request.GetJSON("/jsonschema")
+	request.AssertOK()
+
+	// it should work with string
+	request.AssertJSONSchema(schema)
+
+	// it should work with file
+	filename := filepath.Join(t.TempDir(), "schema.json")
+	if it.Nil(os.WriteFile(filename, []byte(schema), 0644)) {
+		request.AssertJSONSchema(filename)
+	}
+
+	// it should work with Go value of draft-07
+	request.AssertJSONSchema(map[string]interface{}{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type":    "object",
+		"properties": map[string]interface{}{
+			"addresses": map[string]interface{}{
+				"type":     "array",
+				"maxItems": 2,
+			},
+		},
+	})
+
+	// it should report every violation with JSON pointer
+	harness := &mockTesting{}
+
+	request = New(ts.URL).New(harness)
+	request.GetJSON("/jsonschema")
+	it.False(request.AssertJSONSchema(strings.NewReader(`{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type": "object",
+		"properties": {
+			"user": {
+				"type": "object",
+				"properties": {
+					"age": {"type": "string"},
+					"email": {"type": "string", "format": "hostname"}
+				}
+			},
+			"addresses": {
+				"type": "array",
+				"items": {"type": "object", "required": ["zip"]}
+			}
+		}
+	}`)))
+	if it.Len(harness.errors, 1) {
+		it.Contains(harness.errors[0], "4 violation(s)")
+		it.Contains(harness.errors[0], "/user/age")
+		it.Contains(harness.errors[0], "/user/email")
+		it.Contains(harness.errors[0], "/addresses/0")
+		it.Contains(harness.errors[0], "/addresses/1")
+	}
+
+	// it should fail with invalid schema
+	harness = &mockTesting{}
+
+	request = New(ts.URL).New(harness)
+	request.GetJSON("/jsonschema")
+	it.False(request.AssertJSONSchema(`{"type": 1}`))
+	it.Len(harness.errors, 1)
+}
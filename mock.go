@@ -0,0 +1,364 @@
+package httptesting
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golib/assert"
+)
+
+// MockServer defines a programmable http.Handler of routes declared by On, it verifies
+// expectations of routes when Close of the client created by NewMockServer.
+type MockServer struct {
+	mux        sync.Mutex
+	t          TestingT
+	basePath   string
+	routes     []*MockRoute
+	unexpected []string
+}
+
+// MockRoute defines a route of MockServer with matchers of request, reply and expectation of calls.
+type MockRoute struct {
+	method   string
+	pattern  string
+	segments []string
+	header   http.Header
+	query    map[string]string
+	body     func(body []byte) bool
+	bodyDesc string
+
+	status  int
+	reply   http.Header
+	content []byte
+	handler http.HandlerFunc
+
+	times int
+	calls int
+}
+
+// NewMockServer returns an initialized *Client along with a *MockServer for declaring routes.
+// Unmet expectations of routes fail the test when client.Close() runs.
+// NOTE: You MUST call client.Close() for cleanup after testing.
+func NewMockServer(t TestingT, opts ...Option) (*Client, *MockServer) {
+	mock := &MockServer{
+		t: t,
+	}
+
+	client := NewServer(mock, opts...)
+	client.mock = mock
+
+	// NOTE: patterns of routes are relative to base path of the client, as paths of requests
+	mock.basePath = client.basePath
+
+	return client, mock
+}
+
+// On declares a route for the method and pattern relative to base path of the client. Segments of pattern in form of {name} match any
+// value of a segment, which are available by r.PathValue(name) of handler given by ReplyFunc.
+//
+// NOTE: Routes are matched in order of declaration, and a route with expectation of Times is skipped
+// after called n times if another route matches.
+func (mock *MockServer) On(method, pattern string) *MockRoute {
+	route := &MockRoute{
+		method:   strings.ToUpper(method),
+		pattern:  pattern,
+		segments: splitPath(mock.basePath + pattern),
+		header:   http.Header{},
+		query:    map[string]string{},
+		status:   http.StatusOK,
+		reply:    http.Header{},
+		times:    -1,
+	}
+
+	mock.mux.Lock()
+	mock.routes = append(mock.routes, route)
+	mock.mux.Unlock()
+
+	return route
+}
+
+// ServeHTTP implements http.Handler.
+func (mock *MockServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	route := mock.match(r, body)
+	if route == nil {
+		http.Error(w, fmt.Sprintf("httptesting: no route of MockServer matches %s %s", r.Method, r.URL.RequestURI()), http.StatusNotFound)
+		return
+	}
+
+	if route.handler != nil {
+		route.handler(w, r)
+		return
+	}
+
+	for key, values := range route.reply {
+		w.Header()[key] = values
+	}
+	w.WriteHeader(route.status)
+	w.Write(route.content)
+}
+
+// AssertExpectations asserts that all routes are called as expected, and no request matches none of routes.
+// It is invoked by Close of the client created by NewMockServer.
+func (mock *MockServer) AssertExpectations() bool {
+	mock.mux.Lock()
+	defer mock.mux.Unlock()
+
+	var failures []string
+	for _, route := range mock.routes {
+		if route.times >= 0 && route.calls != route.times {
+			failures = append(failures, fmt.Sprintf("\t%s: expected %d call(s), but got %d", route, route.times, route.calls))
+		}
+	}
+
+	for _, request := range mock.unexpected {
+		failures = append(failures, fmt.Sprintf("\t%s: unexpected request matching none of routes", request))
+	}
+
+	if len(failures) == 0 {
+		return true
+	}
+
+	return assert.Fail(mock.t, "MockServer: expectations (*unmet)",
+		"Expected all expectations of MockServer met, but got %d failure(s):\n%s",
+		len(failures),
+		strings.Join(failures, "\n"),
+	)
+}
+
+func (mock *MockServer) match(r *http.Request, body []byte) *MockRoute {
+	mock.mux.Lock()
+	defer mock.mux.Unlock()
+
+	var (
+		matched *MockRoute
+		params  map[string]string
+	)
+	for _, route := range mock.routes {
+		values, ok := route.match(r, body)
+		if !ok {
+			continue
+		}
+
+		if matched == nil {
+			matched, params = route, values
+		}
+
+		// prefer route which expects more calls
+		if route.times < 0 || route.calls < route.times {
+			matched, params = route, values
+			break
+		}
+	}
+
+	if matched == nil {
+		mock.unexpected = append(mock.unexpected, r.Method+" "+r.URL.RequestURI())
+		return nil
+	}
+
+	matched.calls++
+	for name, value := range params {
+		r.SetPathValue(name, value)
+	}
+
+	return matched
+}
+
+// WithHeader requires requests having header of the value.
+func (route *MockRoute) WithHeader(key, value string) *MockRoute {
+	route.header.Add(key, value)
+
+	return route
+}
+
+// WithQuery requires requests having query parameter of the value.
+func (route *MockRoute) WithQuery(key, value string) *MockRoute {
+	route.query[key] = value
+
+	return route
+}
+
+// WithBody requires requests having body equal to body given. A string or []byte is compared byte by byte,
+// and any other Go value is compared with JSON of request body semantically.
+func (route *MockRoute) WithBody(body interface{}) *MockRoute {
+	switch typo := body.(type) {
+	case string:
+		route.body = func(data []byte) bool {
+			return string(data) == typo
+		}
+		route.bodyDesc = typo
+
+	case []byte:
+		route.body = func(data []byte) bool {
+			return bytes.Equal(data, typo)
+		}
+		route.bodyDesc = string(typo)
+
+	default:
+		expected, err := normalizeJSON(body)
+		route.body = func(data []byte) bool {
+			if err != nil {
+				return false
+			}
+
+			actual, err := decodeJSON(data)
+			if err != nil {
+				return false
+			}
+
+			return equalJSON(expected, actual)
+		}
+		route.bodyDesc = jsonString(expected)
+	}
+
+	return route
+}
+
+// Reply sets status code and body of response for the route. A string or []byte of body is written as is,
+// and any other Go value is written as JSON with Content-Type: application/json header.
+func (route *MockRoute) Reply(status int, body ...interface{}) *MockRoute {
+	route.status = status
+
+	if len(body) > 0 {
+		switch typo := body[0].(type) {
+		case string:
+			route.content = []byte(typo)
+
+		case []byte:
+			route.content = typo
+
+		default:
+			data, err := json.Marshal(typo)
+			if err != nil {
+				panic(fmt.Sprintf("httptesting: Reply: %v", err))
+			}
+
+			route.content = data
+			if len(route.reply.Get("Content-Type")) == 0 {
+				route.reply.Set("Content-Type", "application/json")
+			}
+		}
+	}
+
+	return route
+}
+
+// ReplyHeader adds header to response of the route.
+func (route *MockRoute) ReplyHeader(key, value string) *MockRoute {
+	route.reply.Add(key, value)
+
+	return route
+}
+
+// ReplyFunc sets handler for serving requests of the route, which takes precedence over Reply.
+func (route *MockRoute) ReplyFunc(handler http.HandlerFunc) *MockRoute {
+	route.handler = handler
+
+	return route
+}
+
+// Times expects the route called exactly n times.
+func (route *MockRoute) Times(n int) *MockRoute {
+	route.times = n
+
+	return route
+}
+
+// Never expects the route never called.
+func (route *MockRoute) Never() *MockRoute {
+	return route.Times(0)
+}
+
+// String returns description of the route, e.g. "GET /users/{id}?active=true".
+func (route *MockRoute) String() string {
+	s := route.method + " " + route.pattern
+	if len(route.query) > 0 {
+		var params []string
+		for key, value := range route.query {
+			params = append(params, key+"="+value)
+		}
+		sort.Strings(params)
+
+		s += "?" + strings.Join(params, "&")
+	}
+	if len(route.header) > 0 {
+		s += fmt.Sprintf(" with header %v", map[string][]string(route.header))
+	}
+	if route.body != nil {
+		s += " with body " + route.bodyDesc
+	}
+
+	return s
+}
+
+// match reports whether the request matches the route, and returns values of path parameters.
+func (route *MockRoute) match(r *http.Request, body []byte) (map[string]string, bool) {
+	if route.method != "*" && route.method != r.Method {
+		return nil, false
+	}
+
+	params, ok := matchPath(route.segments, r.URL.Path)
+	if !ok {
+		return nil, false
+	}
+
+	for key, values := range route.header {
+		for _, value := range values {
+			if !containsString(r.Header.Values(key), value) {
+				return nil, false
+			}
+		}
+	}
+
+	query := r.URL.Query()
+	for key, value := range route.query {
+		if !containsString(query[key], value) {
+			return nil, false
+		}
+	}
+
+	if route.body != nil && !route.body(body) {
+		return nil, false
+	}
+
+	return params, true
+}
+
+func splitPath(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+// matchPath reports whether path matches segments of pattern, and returns values of segments
+// in form of {name}.
+func matchPath(pattern []string, path string) (map[string]string, bool) {
+	segments := splitPath(path)
+	if len(segments) != len(pattern) {
+		return nil, false
+	}
+
+	params := map[string]string{}
+	for i, segment := range pattern {
+		if len(segment) > 2 && segment[0] == '{' && segment[len(segment)-1] == '}' {
+			params[segment[1:len(segment)-1]] = segments[i]
+			continue
+		}
+
+		if segment != segments[i] {
+			return nil, false
+		}
+	}
+
+	return params, true
+}
@@ -0,0 +1,123 @@
+package httptesting
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+func TestNewMockServer(t *testing.T) {
+	it := assert.New(t)
+
+	client, mock := NewMockServer(t)
+	defer client.Close()
+
+	mock.On("GET", "/users/{id}").
+		WithHeader("X-Mock-Client", "httptesting").
+		Reply(http.StatusOK, map[string]interface{}{"name": "httptesting"}).
+		Times(2)
+	mock.On("GET", "/users/{id}").
+		Reply(http.StatusNotFound, "not found")
+	mock.On("POST", "/users").
+		WithBody(map[string]interface{}{"name": "httptesting", "age": 3}).
+		ReplyHeader("Location", "/users/1").
+		Reply(http.StatusCreated)
+	mock.On("GET", "/search").
+		WithQuery("q", "httptesting").
+		ReplyFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("search " + r.URL.Query().Get("q")))
+		})
+	mock.On("GET", "/orders/{id}").
+		ReplyFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("order " + r.PathValue("id")))
+		})
+	mock.On("DELETE", "/users/{id}").Never()
+
+	request := client.New(t)
+	request.WithHeader("X-Mock-Client", "httptesting")
+	request.GetJSON("/users/1")
+	request.AssertOK()
+	request.AssertHeader("Content-Type", "application/json")
+	request.AssertContainsJSON("name", "httptesting")
+	request.GetJSON("/users/2")
+	request.AssertOK()
+
+	// it should fallback to next route after called expected times
+	request.GetJSON("/users/3")
+	request.AssertNotFound()
+	request.AssertContains("not found")
+
+	request = client.New(t)
+	request.PostJSON("/users", map[string]interface{}{"age": 3, "name": "httptesting"})
+	request.AssertStatus(http.StatusCreated)
+	request.AssertHeader("Location", "/users/1")
+
+	request.Get("/search", url.Values{"q": []string{"httptesting"}})
+	request.AssertOK()
+	request.AssertContains("search httptesting")
+
+	request.Get("/orders/2020")
+	request.AssertContains("order 2020")
+
+	it.True(mock.AssertExpectations())
+}
+
+func TestNewMockServerWithBasePath(t *testing.T) {
+	it := assert.New(t)
+
+	client, mock := NewMockServer(t, WithBasePath("/api"))
+	defer client.Close()
+
+	mock.On("GET", "/users/{id}").
+		ReplyFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("user " + r.PathValue("id")))
+		}).
+		Times(1)
+
+	// it should match routes relative to base path
+	request := client.New(t)
+	request.Get("/users/1")
+	request.AssertOK()
+	request.AssertContains("user 1")
+
+	it.True(mock.AssertExpectations())
+}
+
+func TestMockServer_AssertExpectations(t *testing.T) {
+	it := assert.New(t)
+
+	harness := &mockTesting{}
+
+	client, mock := NewMockServer(harness)
+
+	mock.On("GET", "/users/{id}").Reply(http.StatusOK).Times(2)
+	mock.On("DELETE", "/users/{id}").Never()
+	mock.On("POST", "/users").WithBody("name=httptesting").Reply(http.StatusCreated).Times(1)
+
+	request := client.New(t)
+	request.Get("/users/1")
+	request.AssertOK()
+	request.Delete("/users/1", "")
+	request.AssertOK()
+	request.Post("/users", "text/plain", []byte("name=unknown"))
+	request.AssertNotFound()
+	request.AssertContains("no route of MockServer matches POST /users")
+
+	it.Empty(harness.errors)
+
+	client.Close()
+	if it.Len(harness.errors, 1) {
+		it.Contains(harness.errors[0], "4 failure(s)")
+		it.Contains(harness.errors[0], "\tGET /users/{id}: expected 2 call(s), but got 1")
+		it.Contains(harness.errors[0], "\tDELETE /users/{id}: expected 0 call(s), but got 1")
+		it.Contains(harness.errors[0], "\tPOST /users with body name=httptesting: expected 1 call(s), but got 0")
+		it.Contains(harness.errors[0], "\tPOST /users: unexpected request matching none of routes")
+	}
+
+	// it should not assert twice
+	client.Close()
+	it.Len(harness.errors, 1)
+}
@@ -0,0 +1,187 @@
+package httptesting
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Option defines a callback for customizing *Client, it is shared by New, NewWithTLS, NewServer and NewServerWithTLS.
+type Option func(*Client)
+
+// WithTLS enables https scheme for the client.
+//
+// NOTE: NewServer starts a TLS server with this option.
+func WithTLS() Option {
+	return func(c *Client) {
+		c.isTLS = true
+	}
+}
+
+// WithRootCAs sets root certificate authorities used to verify server certificates instead of the system pool.
+func WithRootCAs(certs *x509.CertPool) Option {
+	return func(c *Client) {
+		c.certs = certs
+	}
+}
+
+// WithStrictTLS enables verification of server certificates, which reverts WithInsecureSkipVerify option.
+//
+// Deprecated: Server certificates are verified by default against root certificate authorities given
+// by WithRootCAs, or the system pool if absent.
+func WithStrictTLS() Option {
+	return func(c *Client) {
+		c.insecureTLS = false
+	}
+}
+
+// WithInsecureSkipVerify disables verification of server certificates, including the hostname.
+// It takes precedence over WithRootCAs and WithServerName options.
+func WithInsecureSkipVerify() Option {
+	return func(c *Client) {
+		c.insecureTLS = true
+	}
+}
+
+// WithServerName sets server name used to verify the hostname of server certificates,
+// it is useful for connecting server with IP address.
+func WithServerName(name string) Option {
+	return func(c *Client) {
+		c.serverName = name
+	}
+}
+
+// WithClientCert adds a certificate presented to servers which require client certificates.
+func WithClientCert(cert tls.Certificate) Option {
+	return func(c *Client) {
+		c.clientCerts = append(c.clientCerts, cert)
+	}
+}
+
+// WithClientAuth sets policy of client certificates for server created by NewServer or NewServerWithTLS,
+// client certificates are verified against cas given.
+func WithClientAuth(policy tls.ClientAuthType, cas *x509.CertPool) Option {
+	return func(c *Client) {
+		c.clientAuth = policy
+		c.clientCAs = cas
+	}
+}
+
+// WithTimeout sets time limit for requests made by the client, including reading response body.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithDefaultHeaders sets headers applied to every request created by the client.
+func WithDefaultHeaders(header http.Header) Option {
+	return func(c *Client) {
+		for key, values := range header {
+			for _, value := range values {
+				c.header.Add(key, value)
+			}
+		}
+	}
+}
+
+// WithJar replaces the default cookie jar of the client. A nil jar disables cookies.
+func WithJar(jar http.CookieJar) Option {
+	return func(c *Client) {
+		c.jar = jar
+	}
+}
+
+// WithBasePath sets prefix of path for all requests, e.g. "/api/v1".
+func WithBasePath(prefix string) Option {
+	return func(c *Client) {
+		prefix = strings.TrimRight(prefix, "/")
+		if len(prefix) > 0 && prefix[0] != '/' {
+			prefix = "/" + prefix
+		}
+
+		c.basePath = prefix
+	}
+}
+
+// WithTransport sets http.RoundTripper used underneath request filters instead of creating one from TransportConfig.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Client) {
+		c.transport = transport
+	}
+}
+
+// WithMiddlewares wraps the underlying transport of requests with middlewares, the first of which is the outermost.
+// They are invoked once for each request after request filters, including redirects.
+func WithMiddlewares(middlewares ...Middleware) Option {
+	return func(c *Client) {
+		c.middlewares = append(c.middlewares, middlewares...)
+	}
+}
+
+// WithTransportConfig sets timeouts and connection pool settings of the underlying transport.
+// It is ignored when WithTransport option given.
+func WithTransportConfig(config TransportConfig) Option {
+	return func(c *Client) {
+		c.transportConfig = config
+	}
+}
+
+// WithCassette records interactions of requests into cassette, or replays interactions recorded by cassette
+// without network, see NewCassette for details. The cassette is saved by Close of the client.
+func WithCassette(cassette *Cassette) Option {
+	return func(c *Client) {
+		c.cassette = cassette
+	}
+}
+
+// WithRecordRequests records inbound requests of server created by NewServer, NewServerWithTLS or NewHandler
+// for ReceivedRequests and AssertReceived, keeping the latest limit of them. There is no limit if limit <= 0.
+func WithRecordRequests(limit int) Option {
+	return func(c *Client) {
+		c.recordRequests = true
+		c.recordLimit = limit
+	}
+}
+
+// WithFaults injects faults into requests made by the client with injector given, see NewFaultInjector for details.
+func WithFaults(injector *FaultInjector) Option {
+	return func(c *Client) {
+		c.faults = injector
+	}
+}
+
+// WithHAR captures every exchange of requests made by the client into file of HTTP Archive (HAR) 1.2 format,
+// including each hop of redirects with timings, headers and bodies. Values of Authorization, Proxy-Authorization,
+// Cookie and Set-Cookie headers and cookies are redacted. The file is written by Close of the client.
+func WithHAR(filename string) Option {
+	return func(c *Client) {
+		c.har = &harRecorder{
+			filename: filename,
+		}
+	}
+}
+
+// WithColor colors diff of failure messages with ANSI escape codes, e.g. AssertJSONEqual,
+// which is disabled by default for plain output of CI logs.
+func WithColor() Option {
+	return func(c *Client) {
+		c.color = true
+	}
+}
+
+// WithVerbose turns all requests of the client into debug mode, see Request.Debug for details.
+func WithVerbose() Option {
+	return func(c *Client) {
+		c.verbose = true
+	}
+}
+
+// WithDebugBodyLimit sets max size of body logged in debug mode, zero or negative means no limit.
+func WithDebugBodyLimit(limit int) Option {
+	return func(c *Client) {
+		c.debugBodyLimit = limit
+	}
+}
@@ -2,14 +2,27 @@ package httptesting
 
 import (
 	"bytes"
+	"context"
+	"crypto/x509"
+	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
+	"net/http/httptrace"
 	"os"
 	"sync"
-	"testing"
+	"time"
 )
 
+// TestingT defines the minimal interface of testing.TB used by httptesting, which makes it
+// possible to work with *testing.T, *testing.B, *testing.F and custom test harness.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+	Logf(format string, args ...interface{})
+}
+
 // Request defines http client for human usage.
 type Request struct {
 	*Client
@@ -17,19 +30,37 @@ type Request struct {
 	Response     *http.Response
 	ResponseBody []byte
 
-	mux     sync.Mutex
-	t       *testing.T
-	cookies []*http.Cookie
-	header  http.Header
+	mux      sync.Mutex
+	t        TestingT
+	cookies  []*http.Cookie
+	header   http.Header
+	ctx      context.Context
+	timeout  time.Duration
+	err      error
+	nonFatal bool
+	debug    bool
+
+	lastRequest     *http.Request
+	lastRequestBody []byte
+	curlBodyFile    string
+	trace           *requestTrace
+
+	strictDecoding bool
+	xmlNamespaces  map[string]string
 }
 
 // NewRequest returns a new *Request with *Client
-func NewRequest(t *testing.T, client *Client) *Request {
+func NewRequest(t TestingT, client *Client) *Request {
+	header := http.Header{}
+	for key, values := range client.header {
+		header[key] = append([]string(nil), values...)
+	}
+
 	return &Request{
 		Client:  client,
 		t:       t,
 		cookies: []*http.Cookie{},
-		header:  http.Header{},
+		header:  header,
 	}
 }
 
@@ -67,44 +98,96 @@ func (r *Request) WithCookies(cookies []*http.Cookie) *Request {
 	return r
 }
 
-// NewRequest issues any request and read the response.
-// If successful, the caller may examine the Response and ResponseBody properties.
-// NOTE: You have to manage session / cookie data manually.
-func (r *Request) NewRequest(request *http.Request, filters ...RequestFilter) {
+// WithContext sets context for the request, the request is canceled when ctx is done.
+func (r *Request) WithContext(ctx context.Context) *Request {
 	r.mux.Lock()
 	defer r.mux.Unlock()
 
-	var err error
+	r.ctx = ctx
 
-	r.Response, err = r.NewClient(filters...).Do(request)
-	if err != nil {
-		r.t.Fatalf("httptesting: %v\n", err)
+	return r
+}
+
+// WithTimeout sets deadline for the request, including reading response body.
+func (r *Request) WithTimeout(timeout time.Duration) *Request {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	r.timeout = timeout
+
+	return r
+}
+
+// NonFatal turns the request into non-fatal mode, which records errors for Err() instead of
+// failing the test with t.Fatalf.
+func (r *Request) NonFatal() *Request {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	r.nonFatal = true
+
+	return r
+}
+
+// Err returns error of the latest request, it is useful with non-fatal mode.
+func (r *Request) Err() error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	return r.err
+}
+
+// PeerCertificates returns certificate chain presented by client over connection of the latest request,
+// which is seen by server created by NewServer or NewServerWithTLS.
+func (r *Request) PeerCertificates() []*x509.Certificate {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if r.trace == nil {
+		return nil
 	}
-	defer r.Response.Body.Close()
 
-	// Read response body if not empty
-	r.ResponseBody = []byte{}
+	addr := handlerRemoteAddr
+	if !r.Client.inMemory {
+		r.trace.mux.Lock()
+		addr = r.trace.localAddr
+		r.trace.mux.Unlock()
+	}
 
-	switch r.Response.StatusCode {
-	case http.StatusNoContent:
-		// ignore
+	r.Client.receivedMux.Lock()
+	defer r.Client.receivedMux.Unlock()
 
-	default:
-		r.ResponseBody, err = io.ReadAll(r.Response.Body)
-		if err != nil {
-			if err != io.EOF {
-				r.t.Fatalf("httptesting: NewRequest:%s %s: %v\n", request.Method, request.URL.RequestURI(), err)
-			}
+	return r.Client.connPeerCerts[addr]
+}
 
-			r.t.Logf("httptesting: NewRequest:%s %s: Unexptected response body with io.EOF\n", request.Method, request.URL.RequestURI())
-		}
+// NewRequest issues any request and read the response.
+// If successful, the caller may examine the Response and ResponseBody properties.
+// NOTE: You have to manage session / cookie data manually.
+func (r *Request) NewRequest(request *http.Request, filters ...RequestFilter) {
+	r.t.Helper()
+
+	if err := r.do(request, filters); err != nil {
+		r.fail(err)
 	}
 }
 
+// Do issues any request and read the response like NewRequest, but returns error instead of failing the test.
+func (r *Request) Do(request *http.Request, filters ...RequestFilter) error {
+	return r.do(request, filters)
+}
+
 // NewSessionRequest issues any request with session / cookie and read the response.
 // If successful, the caller may examine the Response and ResponseBody properties.
 // NOTE: Session data will be added to the request jar for requested host.
 func (r *Request) NewSessionRequest(request *http.Request, filters ...RequestFilter) {
+	r.t.Helper()
+
+	if err := r.newSessionRequest(request, filters); err != nil {
+		r.fail(err)
+	}
+}
+
+func (r *Request) newSessionRequest(request *http.Request, filters []RequestFilter) error {
 	if cookies, err := r.Cookies(); err == nil {
 		for _, cookie := range cookies {
 			request.AddCookie(cookie)
@@ -115,42 +198,177 @@ func (r *Request) NewSessionRequest(request *http.Request, filters ...RequestFil
 		request.AddCookie(cookie)
 	}
 
-	r.NewRequest(request, filters...)
+	return r.do(request, filters)
+}
+
+func (r *Request) do(request *http.Request, filters []RequestFilter) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	r.Response = nil
+	r.ResponseBody = nil
+	r.err = nil
+	r.lastRequest = nil
+	r.lastRequestBody = nil
+	if len(r.curlBodyFile) > 0 {
+		os.Remove(r.curlBodyFile)
+		r.curlBodyFile = ""
+	}
+	r.trace = nil
+
+	if r.ctx != nil || r.timeout > 0 {
+		ctx := r.ctx
+		if ctx == nil {
+			ctx = request.Context()
+		}
+
+		if r.timeout > 0 {
+			var cancel context.CancelFunc
+
+			ctx, cancel = context.WithTimeout(ctx, r.timeout)
+			defer cancel()
+		}
+
+		request = request.WithContext(ctx)
+	}
+
+	// keep a copy of body for reproducing the request
+	requestBody, err := readRequestBody(request)
+	if err != nil {
+		r.err = &Error{
+			Op:     "NewRequest",
+			Method: request.Method,
+			URL:    request.URL.RequestURI(),
+			Err:    err,
+		}
+
+		return r.err
+	}
+
+	r.lastRequest = request
+	r.lastRequestBody = requestBody
+
+	if r.debug || r.Client.verbose {
+		defer r.dump()
+	}
+
+	trace := newRequestTrace()
+	r.trace = trace
+
+	request = request.WithContext(httptrace.WithClientTrace(request.Context(), trace.clientTrace()))
+
+	defer trace.finish()
+
+	response, err := r.NewClient(filters...).Do(request)
+	if err != nil {
+		r.err = &Error{
+			Op:     "NewRequest",
+			Method: request.Method,
+			URL:    request.URL.RequestURI(),
+			Err:    err,
+		}
+
+		return r.err
+	}
+	defer response.Body.Close()
+
+	r.Response = response
+	if response.Request != nil {
+		r.lastRequest = response.Request
+
+		// body of the request is dropped by redirect, e.g. POST => GET
+		if response.Request.Response != nil {
+			r.lastRequestBody, _ = readRequestBody(response.Request)
+		}
+	}
+
+	// Read response body if not empty
+	r.ResponseBody = []byte{}
+
+	switch response.StatusCode {
+	case http.StatusNoContent:
+		// ignore
+
+	default:
+		r.ResponseBody, err = io.ReadAll(response.Body)
+		if err != nil {
+			r.err = &Error{
+				Op:     "NewRequest",
+				Method: request.Method,
+				URL:    request.URL.RequestURI(),
+				Err:    err,
+			}
+
+			return r.err
+		}
+	}
+
+	return nil
+}
+
+// fail records err and fails the test with t.Fatalf unless the request is in non-fatal mode.
+func (r *Request) fail(err error) {
+	r.mux.Lock()
+	r.Response = nil
+	r.ResponseBody = nil
+	r.err = err
+	nonFatal := r.nonFatal
+	r.mux.Unlock()
+
+	if !nonFatal {
+		r.t.Helper()
+		r.t.Fatalf("%v\n", err)
+	}
 }
 
 // NewMultipartRequest issues a multipart request for the method & fields given and read the response.
 // If successful, the caller may examine the Response and ResponseBody properties.
 func (r *Request) NewMultipartRequest(method, path, filename string, file interface{}, fields ...map[string]string) {
+	r.t.Helper()
+
 	var buf bytes.Buffer
 
 	mw := multipart.NewWriter(&buf)
 
-	fw, ferr := mw.CreateFormFile("filename", filename)
-	if ferr != nil {
-		r.t.Fatalf("httptesting: NewMultipartRequest:%s %s: %v\n", method, path, ferr)
+	fail := func(err error) {
+		r.fail(&Error{
+			Op:     "NewMultipartRequest",
+			Method: method,
+			URL:    path,
+			Err:    err,
+		})
+	}
+
+	fw, err := mw.CreateFormFile("filename", filename)
+	if err != nil {
+		fail(err)
+		return
 	}
 
 	// apply file
-	var (
-		reader io.Reader
-		err    error
-	)
+	var reader io.Reader
 	switch f := file.(type) {
 	case io.Reader:
 		reader = f
 
 	case string:
-		reader, err = os.Open(f)
+		fd, err := os.Open(f)
 		if err != nil {
-			r.t.Fatalf("httptesting: NewMultipartRequest:os.Open(%s): %v\n", f, err)
+			fail(err)
+			return
 		}
+		defer fd.Close()
+
+		reader = fd
 
 	default:
-		r.t.Fatalf("httptesting: NewMultipartRequest:%T<%v>: Unsupported file type\n", file, file)
+		fail(fmt.Errorf("%T<%v>: Unsupported file type", file, file))
+		return
 	}
 
 	if _, err := io.Copy(fw, reader); err != nil {
-		r.t.Fatalf("httptesting: NewMultipartRequest:io.Copy(%T, %T): %v\n", fw, file, err)
+		fail(err)
+		return
 	}
 
 	// apply fields
@@ -165,9 +383,40 @@ func (r *Request) NewMultipartRequest(method, path, filename string, file interf
 
 	request, err := http.NewRequest(method, r.Url(path), &buf)
 	if err != nil {
-		r.t.Fatalf("httptesting: NewMultipartRequest:%s %s: %v\n", method, path, err)
+		fail(err)
+		return
 	}
 	request.Header.Set("Content-Type", mw.FormDataContentType())
 
 	r.NewRequest(request)
 }
+
+// readRequestBody returns a copy of request body, and leaves the request readable.
+func readRequestBody(request *http.Request) ([]byte, error) {
+	if request.Body == nil || request.Body == http.NoBody {
+		return nil, nil
+	}
+
+	if request.GetBody != nil {
+		body, err := request.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+
+		return io.ReadAll(body)
+	}
+
+	data, err := io.ReadAll(request.Body)
+	request.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	request.Body = io.NopCloser(bytes.NewReader(data))
+	request.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+
+	return data, nil
+}
@@ -88,7 +88,13 @@ func (r *Request) PutForm(path string, data interface{}) {
 func (r *Request) PutJSON(path string, data interface{}) {
 	b, err := json.Marshal(data)
 	if err != nil {
-		r.t.Fatalf("httptesting: PutJSON:json.Marshal(%T): %v", data, err)
+		r.fail(&Error{
+			Op:     "PutJSON",
+			Method: "PUT",
+			URL:    path,
+			Err:    err,
+		})
+		return
 	}
 
 	r.Put(path, "application/json", b)
@@ -100,7 +106,13 @@ func (r *Request) PutJSON(path string, data interface{}) {
 func (r *Request) PutXML(path string, data interface{}) {
 	b, err := xml.Marshal(data)
 	if err != nil {
-		r.t.Fatalf("httptesting: PutXML:xml.Marshal(%T): %v", data, err)
+		r.fail(&Error{
+			Op:     "PutXML",
+			Method: "PUT",
+			URL:    path,
+			Err:    err,
+		})
+		return
 	}
 
 	r.Put(path, "text/xml", b)
@@ -124,7 +136,13 @@ func (r *Request) PostForm(path string, data interface{}) {
 func (r *Request) PostJSON(path string, data interface{}) {
 	b, err := json.Marshal(data)
 	if err != nil {
-		r.t.Fatalf("httptesting: PostJSON:json.Marshal(%T): %v", data, err)
+		r.fail(&Error{
+			Op:     "PostJSON",
+			Method: "POST",
+			URL:    path,
+			Err:    err,
+		})
+		return
 	}
 
 	r.Post(path, "application/json", b)
@@ -136,7 +154,13 @@ func (r *Request) PostJSON(path string, data interface{}) {
 func (r *Request) PostXML(path string, data interface{}) {
 	b, err := xml.Marshal(data)
 	if err != nil {
-		r.t.Fatalf("httptesting: PostXML:xml.Marshal(%T): %v", data, err)
+		r.fail(&Error{
+			Op:     "PostXML",
+			Method: "POST",
+			URL:    path,
+			Err:    err,
+		})
+		return
 	}
 
 	r.Post(path, "text/xml", b)
@@ -160,7 +184,13 @@ func (r *Request) PatchForm(path string, data interface{}) {
 func (r *Request) PatchJSON(path string, data interface{}) {
 	b, err := json.Marshal(data)
 	if err != nil {
-		r.t.Fatalf("httptesting: PatchJSON:json.Marshal(%T): %v", data, err)
+		r.fail(&Error{
+			Op:     "PatchJSON",
+			Method: "PATCH",
+			URL:    path,
+			Err:    err,
+		})
+		return
 	}
 
 	r.Patch(path, "application/json", b)
@@ -172,7 +202,13 @@ func (r *Request) PatchJSON(path string, data interface{}) {
 func (r *Request) PatchXML(path string, data interface{}) {
 	b, err := xml.Marshal(data)
 	if err != nil {
-		r.t.Fatalf("httptesting: PatchXML:xml.Marshal(%T): %v", data, err)
+		r.fail(&Error{
+			Op:     "PatchXML",
+			Method: "PATCH",
+			URL:    path,
+			Err:    err,
+		})
+		return
 	}
 
 	r.Patch(path, "text/xml", b)
@@ -196,7 +232,13 @@ func (r *Request) DeleteForm(path string, data interface{}) {
 func (r *Request) DeleteJSON(path string, data interface{}) {
 	b, err := json.Marshal(data)
 	if err != nil {
-		r.t.Fatalf("httptesting: DeleteJSON:json.Marshal(%T): %v", data, err)
+		r.fail(&Error{
+			Op:     "DeleteJSON",
+			Method: "DELETE",
+			URL:    path,
+			Err:    err,
+		})
+		return
 	}
 
 	r.Delete(path, "application/json", b)
@@ -208,7 +250,13 @@ func (r *Request) DeleteJSON(path string, data interface{}) {
 func (r *Request) DeleteXML(path string, data interface{}) {
 	b, err := xml.Marshal(data)
 	if err != nil {
-		r.t.Fatalf("httptesting: DeleteXML:xml.Marshal(%T): %v", data, err)
+		r.fail(&Error{
+			Op:     "DeleteXML",
+			Method: "DELETE",
+			URL:    path,
+			Err:    err,
+		})
+		return
 	}
 
 	r.Delete(path, "text/xml", b)
@@ -218,9 +266,27 @@ func (r *Request) DeleteXML(path string, data interface{}) {
 // stores the result in Response and ResponseBody if success.
 // NOTE: It will encode data with json.Marshal for unsupported types and reset content type to application/json for the request.
 func (r *Request) Send(method, path, contentType string, data ...interface{}) {
+	r.t.Helper()
+
+	if err := r.send(method, path, contentType, data); err != nil {
+		r.fail(err)
+	}
+}
+
+// TrySend issues a HTTP request like Send, but returns error instead of failing the test.
+func (r *Request) TrySend(method, path, contentType string, data ...interface{}) error {
+	return r.send(method, path, contentType, data)
+}
+
+func (r *Request) send(method, path, contentType string, data []interface{}) error {
 	request, err := r.Build(method, path, contentType, data...)
 	if err != nil {
-		r.t.Fatalf("httptesting: Send:%s %s: %v\n", method, path, err)
+		return &Error{
+			Op:     "Send",
+			Method: method,
+			URL:    path,
+			Err:    err,
+		}
 	}
 
 	// adjust custom headers
@@ -237,9 +303,10 @@ func (r *Request) Send(method, path, contentType string, data ...interface{}) {
 		}
 	}
 
-	r.NewSessionRequest(request)
+	return r.newSessionRequest(request, nil)
 }
 
+// Build returns a *http.Request for the method, path and content type given, with data encoded as body or query.
 func (r *Request) Build(method, urlpath, contentType string, data ...interface{}) (request *http.Request, err error) {
 	absurl := r.Url(urlpath)
 
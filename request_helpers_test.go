@@ -30,7 +30,7 @@ func TestRequest_Get(t *testing.T) {
 	ts := httptest.NewServer(server)
 	defer ts.Close()
 
-	request := New(ts.URL, false).New(t)
+	request := New(ts.URL).New(t)
 	request.Get(uri, params)
 	request.AssertOK()
 	request.AssertHeader("x-request-method", method)
@@ -56,7 +56,7 @@ func TestRequest_GetJSON(t *testing.T) {
 	ts := httptest.NewServer(server)
 	defer ts.Close()
 
-	request := New(ts.URL, false).New(t)
+	request := New(ts.URL).New(t)
 	request.GetJSON(uri, params)
 	request.AssertOK()
 	request.AssertHeader("x-request-method", method)
@@ -82,7 +82,7 @@ func TestRequest_GetXML(t *testing.T) {
 	ts := httptest.NewServer(server)
 	defer ts.Close()
 
-	request := New(ts.URL, false).New(t)
+	request := New(ts.URL).New(t)
 	request.GetXML(uri, params)
 	request.AssertOK()
 	request.AssertHeader("x-request-method", method)
@@ -105,7 +105,7 @@ func TestRequest_Head(t *testing.T) {
 	ts := httptest.NewServer(server)
 	defer ts.Close()
 
-	request := New(ts.URL, false).New(t)
+	request := New(ts.URL).New(t)
 	request.Head(uri)
 	request.AssertOK()
 	request.AssertHeader("x-request-method", method)
@@ -128,7 +128,7 @@ func TestRequest_Options(t *testing.T) {
 	ts := httptest.NewServer(server)
 	defer ts.Close()
 
-	request := New(ts.URL, false).New(t)
+	request := New(ts.URL).New(t)
 	request.Options(uri)
 	request.AssertOK()
 	request.AssertHeader("x-request-method", method)
@@ -158,7 +158,7 @@ func TestRequest_PutForm(t *testing.T) {
 	ts := httptest.NewServer(server)
 	defer ts.Close()
 
-	request := New(ts.URL, false).New(t)
+	request := New(ts.URL).New(t)
 	request.PutForm(uri, params)
 	request.AssertOK()
 	request.AssertHeader("x-request-method", method)
@@ -196,7 +196,7 @@ func TestRequest_PutJSON(t *testing.T) {
 	ts := httptest.NewServer(server)
 	defer ts.Close()
 
-	request := New(ts.URL, false).New(t)
+	request := New(ts.URL).New(t)
 	request.PutJSON(uri, params)
 	request.AssertOK()
 	request.AssertHeader("x-request-method", method)
@@ -241,7 +241,7 @@ func TestRequest_PutXML(t *testing.T) {
 	ts := httptest.NewServer(server)
 	defer ts.Close()
 
-	request := New(ts.URL, false).New(t)
+	request := New(ts.URL).New(t)
 	request.PutXML(uri, params)
 	request.AssertOK()
 	request.AssertHeader("x-request-method", method)
@@ -271,7 +271,7 @@ func TestRequest_PostForm(t *testing.T) {
 	ts := httptest.NewServer(server)
 	defer ts.Close()
 
-	request := New(ts.URL, false).New(t)
+	request := New(ts.URL).New(t)
 	request.PostForm(uri, params)
 	request.AssertOK()
 	request.AssertHeader("x-request-method", method)
@@ -309,7 +309,7 @@ func TestRequest_PostJSON(t *testing.T) {
 	ts := httptest.NewServer(server)
 	defer ts.Close()
 
-	request := New(ts.URL, false).New(t)
+	request := New(ts.URL).New(t)
 	request.PostJSON(uri, params)
 	request.AssertOK()
 	request.AssertHeader("x-request-method", method)
@@ -354,7 +354,7 @@ func TestRequest_PostXML(t *testing.T) {
 	ts := httptest.NewServer(server)
 	defer ts.Close()
 
-	request := New(ts.URL, false).New(t)
+	request := New(ts.URL).New(t)
 	request.PostXML(uri, params)
 	request.AssertOK()
 	request.AssertHeader("x-request-method", method)
@@ -384,7 +384,7 @@ func TestRequest_PatchForm(t *testing.T) {
 	ts := httptest.NewServer(server)
 	defer ts.Close()
 
-	request := New(ts.URL, false).New(t)
+	request := New(ts.URL).New(t)
 	request.PatchForm(uri, params)
 	request.AssertOK()
 	request.AssertHeader("x-request-method", method)
@@ -422,7 +422,7 @@ func TestRequest_PatchJSON(t *testing.T) {
 	ts := httptest.NewServer(server)
 	defer ts.Close()
 
-	request := New(ts.URL, false).New(t)
+	request := New(ts.URL).New(t)
 	request.PatchJSON(uri, params)
 	request.AssertOK()
 	request.AssertHeader("x-request-method", method)
@@ -467,7 +467,7 @@ func TestRequest_PatchXML(t *testing.T) {
 	ts := httptest.NewServer(server)
 	defer ts.Close()
 
-	request := New(ts.URL, false).New(t)
+	request := New(ts.URL).New(t)
 	request.PatchXML(uri, params)
 	request.AssertOK()
 	request.AssertHeader("x-request-method", method)
@@ -502,7 +502,7 @@ func TestRequest_DeleteForm(t *testing.T) {
 	ts := httptest.NewServer(server)
 	defer ts.Close()
 
-	request := New(ts.URL, false).New(t)
+	request := New(ts.URL).New(t)
 	request.DeleteForm(uri, params)
 	request.AssertOK()
 	request.AssertHeader("x-request-method", method)
@@ -540,7 +540,7 @@ func TestRequest_DeleteJSON(t *testing.T) {
 	ts := httptest.NewServer(server)
 	defer ts.Close()
 
-	request := New(ts.URL, false).New(t)
+	request := New(ts.URL).New(t)
 	request.DeleteJSON(uri, params)
 	request.AssertOK()
 	request.AssertHeader("x-request-method", method)
@@ -585,7 +585,7 @@ func TestRequest_DeleteXML(t *testing.T) {
 	ts := httptest.NewServer(server)
 	defer ts.Close()
 
-	request := New(ts.URL, false).New(t)
+	request := New(ts.URL).New(t)
 	request.DeleteXML(uri, params)
 	request.AssertOK()
 	request.AssertHeader("x-request-method", method)
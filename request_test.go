@@ -1,9 +1,14 @@
 package httptesting
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"sync"
+	"syscall"
 	"testing"
 	"time"
 
@@ -26,7 +31,7 @@ func TestRequest(t *testing.T) {
 	ts := httptest.NewServer(server)
 	defer ts.Close()
 
-	client := New(ts.URL, false)
+	client := New(ts.URL)
 
 	request := client.New(t)
 	request.WithHeader("X-Mock-Client", "httptesting")
@@ -54,7 +59,7 @@ func TestRequestWithConcurrency(t *testing.T) {
 	ts := httptest.NewServer(server)
 	defer ts.Close()
 
-	client := New(ts.URL, false)
+	client := New(ts.URL)
 
 	var (
 		wg sync.WaitGroup
@@ -82,3 +87,193 @@ func TestRequestWithConcurrency(t *testing.T) {
 	delta := time.Since(issuedAt)
 	it.True(delta < 20*time.Millisecond)
 }
+
+func TestRequestWithTestingT(t *testing.T) {
+	it := assert.New(t)
+
+	method := "GET"
+	uri := "/request/testing"
+	server := newMockServer(method, uri, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("httptesting"))
+	})
+
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	harness := &mockTesting{}
+
+	request := New(ts.URL).New(harness)
+	request.Get(uri)
+	it.True(request.AssertOK())
+	it.False(request.AssertNotFound())
+	it.Len(harness.errors, 1)
+	it.Empty(harness.fatals)
+}
+
+func TestRequestWithNonFatal(t *testing.T) {
+	it := assert.New(t)
+
+	method := "GET"
+	uri := "/request/nonfatal"
+	server := newMockServer(method, uri, func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+
+		w.WriteHeader(http.StatusOK)
+	})
+
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	// it should return error of timeout
+	client := New(ts.URL, WithTimeout(10*time.Millisecond))
+
+	request := client.New(t)
+	err := request.TrySend(method, uri, "text/html")
+	if it.NotNil(err) {
+		var reqErr *Error
+		if it.True(errors.As(err, &reqErr)) {
+			it.Equal("NewRequest", reqErr.Op)
+			it.Equal(method, reqErr.Method)
+			it.Equal(uri, reqErr.URL)
+			it.True(reqErr.Timeout())
+		}
+		it.Equal(err, request.Err())
+	}
+	it.Nil(request.Response)
+
+	// it should record error of encoding
+	harness := &mockTesting{}
+
+	request = New(ts.URL).New(harness).NonFatal()
+	request.PostJSON(uri, map[string]interface{}{"chan": make(chan int)})
+	if it.NotNil(request.Err()) {
+		var jsonErr *json.UnsupportedTypeError
+		it.True(errors.As(request.Err(), &jsonErr))
+		it.Contains(request.Err().Error(), "PostJSON:POST "+uri)
+	}
+	it.Empty(harness.fatals)
+	it.False(request.AssertOK())
+	it.Len(harness.errors, 1)
+
+	// it should return error of connection refused
+	ts.Close()
+
+	req, _ := http.NewRequest(method, client.Url(uri), nil)
+
+	request = New(ts.URL).New(t)
+	err = request.Do(req)
+	if it.NotNil(err) {
+		it.True(errors.Is(err, syscall.ECONNREFUSED))
+		it.False(err.(*Error).Timeout())
+	}
+}
+
+func TestRequestWithContext(t *testing.T) {
+	it := assert.New(t)
+
+	canceled := make(chan struct{}, 1)
+
+	method := "GET"
+	uri := "/request/context"
+	server := newMockServer(method, uri, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+			canceled <- struct{}{}
+
+		case <-time.After(time.Second):
+			w.WriteHeader(http.StatusOK)
+		}
+	})
+
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	client := New(ts.URL)
+
+	// it should fail with deadline exceeded
+	request := client.New(t).NonFatal().WithTimeout(20 * time.Millisecond)
+	request.Get(uri)
+	if it.NotNil(request.Err()) {
+		reqErr := request.Err().(*Error)
+		it.True(reqErr.DeadlineExceeded())
+		it.False(reqErr.Canceled())
+		it.Contains(reqErr.Error(), "deadline exceeded")
+	}
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Error("Expected handler observes cancellation of client")
+	}
+
+	// it should fail with canceled context
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	request = client.New(t).WithContext(ctx)
+	err := request.TrySend(method, uri, "text/html")
+	if it.NotNil(err) {
+		reqErr := err.(*Error)
+		it.True(reqErr.Canceled())
+		it.False(reqErr.DeadlineExceeded())
+	}
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Error("Expected handler observes cancellation of client")
+	}
+}
+
+func BenchmarkRequest(b *testing.B) {
+	method := "GET"
+	uri := "/request/benchmark"
+	server := newMockServer(method, uri, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("httptesting"))
+	})
+
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	client := New(ts.URL)
+	defer client.Close()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		request := client.New(b)
+		request.Get(uri)
+		request.AssertOK()
+	}
+}
+
+type mockTesting struct {
+	mux    sync.Mutex
+	errors []string
+	fatals []string
+	logs   []string
+}
+
+func (mock *mockTesting) Helper() {}
+
+func (mock *mockTesting) Errorf(format string, args ...interface{}) {
+	mock.mux.Lock()
+	defer mock.mux.Unlock()
+
+	mock.errors = append(mock.errors, fmt.Sprintf(format, args...))
+}
+
+func (mock *mockTesting) Fatalf(format string, args ...interface{}) {
+	mock.mux.Lock()
+	defer mock.mux.Unlock()
+
+	mock.fatals = append(mock.fatals, fmt.Sprintf(format, args...))
+}
+
+func (mock *mockTesting) Logf(format string, args ...interface{}) {
+	mock.mux.Lock()
+	defer mock.mux.Unlock()
+
+	mock.logs = append(mock.logs, fmt.Sprintf(format, args...))
+}
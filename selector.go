@@ -0,0 +1,414 @@
+package httptesting
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// cssSelector defines a group of CSS selectors separated by comma, which supports
+//
+//   - type and universal selectors, e.g. "form" and "*"
+//   - id and class selectors, e.g. "#login" and ".btn.btn-primary"
+//   - attribute selectors, e.g. "[href]", "[type=submit]", and operators of ~=, |=, ^=, $= and *=
+//   - descendant and child combinators, e.g. "form input" and "ul > li"
+type cssSelector []cssComplex
+
+// cssComplex defines compound selectors joined by combinators.
+type cssComplex struct {
+	compounds   []cssCompound
+	combinators []byte
+}
+
+// cssCompound defines a sequence of simple selectors without combinator.
+type cssCompound struct {
+	tag     string
+	id      string
+	classes []string
+	attrs   []cssAttr
+}
+
+type cssAttr struct {
+	name  string
+	op    string
+	value string
+}
+
+type cssParser struct {
+	s   string
+	pos int
+}
+
+func parseSelector(selector string) (cssSelector, error) {
+	p := &cssParser{s: selector}
+
+	var group cssSelector
+	for {
+		complex, err := p.parseComplex()
+		if err != nil {
+			return nil, err
+		}
+
+		group = append(group, complex)
+
+		p.skipSpace()
+		if p.eof() {
+			return group, nil
+		}
+
+		if p.s[p.pos] != ',' {
+			return nil, p.errorf("unexpected %q", p.s[p.pos])
+		}
+		p.pos++
+	}
+}
+
+func (p *cssParser) parseComplex() (complex cssComplex, err error) {
+	p.skipSpace()
+
+	compound, err := p.parseCompound()
+	if err != nil {
+		return
+	}
+	complex.compounds = append(complex.compounds, compound)
+
+	for {
+		spaced := p.skipSpace()
+		if p.eof() || p.s[p.pos] == ',' {
+			return
+		}
+
+		combinator := byte(' ')
+		switch {
+		case p.s[p.pos] == '>':
+			combinator = '>'
+
+			p.pos++
+			p.skipSpace()
+
+		case !spaced:
+			err = p.errorf("unexpected %q", p.s[p.pos])
+			return
+		}
+
+		compound, err = p.parseCompound()
+		if err != nil {
+			return
+		}
+
+		complex.combinators = append(complex.combinators, combinator)
+		complex.compounds = append(complex.compounds, compound)
+	}
+}
+
+func (p *cssParser) parseCompound() (compound cssCompound, err error) {
+	start := p.pos
+
+	if !p.eof() && p.s[p.pos] == '*' {
+		p.pos++
+	} else {
+		compound.tag = strings.ToLower(p.parseIdent())
+	}
+
+	for !p.eof() {
+		switch p.s[p.pos] {
+		case '#':
+			p.pos++
+
+			compound.id = p.parseIdent()
+			if len(compound.id) == 0 {
+				return compound, p.errorf("expected id")
+			}
+
+		case '.':
+			p.pos++
+
+			class := p.parseIdent()
+			if len(class) == 0 {
+				return compound, p.errorf("expected class")
+			}
+
+			compound.classes = append(compound.classes, class)
+
+		case '[':
+			p.pos++
+
+			attr, err := p.parseAttr()
+			if err != nil {
+				return compound, err
+			}
+
+			compound.attrs = append(compound.attrs, attr)
+
+		default:
+			if p.pos == start {
+				return compound, p.errorf("unexpected %q", p.s[p.pos])
+			}
+
+			return
+		}
+	}
+
+	if p.pos == start {
+		err = p.errorf("expected selector")
+	}
+	return
+}
+
+func (p *cssParser) parseAttr() (attr cssAttr, err error) {
+	p.skipSpace()
+
+	attr.name = strings.ToLower(p.parseIdent())
+	if len(attr.name) == 0 {
+		return attr, p.errorf("expected attribute name")
+	}
+
+	p.skipSpace()
+	if p.eof() {
+		return attr, p.errorf("expected ]")
+	}
+
+	switch p.s[p.pos] {
+	case ']':
+		p.pos++
+		return
+
+	case '=':
+		attr.op = "="
+		p.pos++
+
+	case '~', '|', '^', '$', '*':
+		if p.pos+1 >= len(p.s) || p.s[p.pos+1] != '=' {
+			return attr, p.errorf("expected =")
+		}
+
+		attr.op = p.s[p.pos : p.pos+2]
+		p.pos += 2
+
+	default:
+		return attr, p.errorf("unexpected %q", p.s[p.pos])
+	}
+
+	p.skipSpace()
+	if p.eof() {
+		return attr, p.errorf("expected attribute value")
+	}
+
+	switch quote := p.s[p.pos]; quote {
+	case '"', '\'':
+		end := strings.IndexByte(p.s[p.pos+1:], quote)
+		if end < 0 {
+			return attr, p.errorf("unclosed quote")
+		}
+
+		attr.value = p.s[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+
+	default:
+		attr.value = p.parseIdent()
+	}
+
+	p.skipSpace()
+	if p.eof() || p.s[p.pos] != ']' {
+		return attr, p.errorf("expected ]")
+	}
+	p.pos++
+
+	return
+}
+
+func (p *cssParser) parseIdent() string {
+	var ident strings.Builder
+
+	for !p.eof() {
+		c := p.s[p.pos]
+		switch {
+		case c == '\\' && p.pos+1 < len(p.s):
+			ident.WriteByte(p.s[p.pos+1])
+			p.pos += 2
+			continue
+
+		case c == '-', c == '_', c >= 0x80,
+			c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+			ident.WriteByte(c)
+
+		default:
+			return ident.String()
+		}
+
+		p.pos++
+	}
+
+	return ident.String()
+}
+
+func (p *cssParser) skipSpace() bool {
+	start := p.pos
+	for !p.eof() && strings.IndexByte(" \t\r\n\f", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+
+	return p.pos > start
+}
+
+func (p *cssParser) eof() bool {
+	return p.pos >= len(p.s)
+}
+
+func (p *cssParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid selector %q at %d: %s", p.s, p.pos, fmt.Sprintf(format, args...))
+}
+
+// selectAll returns all element nodes matching the selector within root in document order.
+func (selector cssSelector) selectAll(root *html.Node) []*html.Node {
+	var nodes []*html.Node
+
+	var walk func(node *html.Node)
+	walk = func(node *html.Node) {
+		if selector.match(node) {
+			nodes = append(nodes, node)
+		}
+
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(root)
+
+	return nodes
+}
+
+func (selector cssSelector) match(node *html.Node) bool {
+	for _, complex := range selector {
+		if complex.matchAt(node, len(complex.compounds)-1) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (complex cssComplex) matchAt(node *html.Node, i int) bool {
+	if !complex.compounds[i].match(node) {
+		return false
+	}
+
+	if i == 0 {
+		return true
+	}
+
+	if complex.combinators[i-1] == '>' {
+		return node.Parent != nil && complex.matchAt(node.Parent, i-1)
+	}
+
+	for parent := node.Parent; parent != nil; parent = parent.Parent {
+		if complex.matchAt(parent, i-1) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (compound cssCompound) match(node *html.Node) bool {
+	if node.Type != html.ElementNode {
+		return false
+	}
+
+	if len(compound.tag) > 0 && node.Data != compound.tag {
+		return false
+	}
+
+	if len(compound.id) > 0 {
+		if id, ok := htmlAttr(node, "id"); !ok || id != compound.id {
+			return false
+		}
+	}
+
+	if len(compound.classes) > 0 {
+		class, _ := htmlAttr(node, "class")
+
+		classes := strings.Fields(class)
+		for _, expected := range compound.classes {
+			if !containsString(classes, expected) {
+				return false
+			}
+		}
+	}
+
+	for _, attr := range compound.attrs {
+		value, ok := htmlAttr(node, attr.name)
+		if !ok || !attr.match(value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (attr cssAttr) match(value string) bool {
+	switch attr.op {
+	case "":
+		return true
+
+	case "=":
+		return value == attr.value
+
+	case "~=":
+		return containsString(strings.Fields(value), attr.value)
+
+	case "|=":
+		return value == attr.value || strings.HasPrefix(value, attr.value+"-")
+
+	case "^=":
+		return len(attr.value) > 0 && strings.HasPrefix(value, attr.value)
+
+	case "$=":
+		return len(attr.value) > 0 && strings.HasSuffix(value, attr.value)
+
+	case "*=":
+		return len(attr.value) > 0 && strings.Contains(value, attr.value)
+	}
+
+	return false
+}
+
+func htmlAttr(node *html.Node, name string) (string, bool) {
+	for _, attr := range node.Attr {
+		if len(attr.Namespace) == 0 && attr.Key == name {
+			return attr.Val, true
+		}
+	}
+
+	return "", false
+}
+
+// htmlText returns text content of node with whitespace collapsed.
+func htmlText(node *html.Node) string {
+	var buf strings.Builder
+
+	var walk func(node *html.Node)
+	walk = func(node *html.Node) {
+		if node.Type == html.TextNode {
+			buf.WriteString(node.Data)
+		}
+
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(node)
+
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
+
+func containsString(values []string, s string) bool {
+	for _, value := range values {
+		if value == s {
+			return true
+		}
+	}
+
+	return false
+}
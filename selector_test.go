@@ -0,0 +1,68 @@
+package httptesting
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/golib/assert"
+	"golang.org/x/net/html"
+)
+
+func Test_parseSelector(t *testing.T) {
+	it := assert.New(t)
+
+	selector, err := parseSelector(`div#main.a.b > ul li[data-id|="en"], *[href$='.pdf']`)
+	if it.Nil(err) && it.Len(selector, 2) {
+		complex := selector[0]
+		if it.Len(complex.compounds, 3) {
+			it.Equal(cssCompound{tag: "div", id: "main", classes: []string{"a", "b"}}, complex.compounds[0])
+			it.Equal("ul", complex.compounds[1].tag)
+			it.Equal([]cssAttr{{name: "data-id", op: "|=", value: "en"}}, complex.compounds[2].attrs)
+		}
+		it.Equal([]byte{'>', ' '}, complex.combinators)
+
+		it.Equal([]cssAttr{{name: "href", op: "$=", value: ".pdf"}}, selector[1].compounds[0].attrs)
+	}
+
+	for _, invalid := range []string{"", "div,", "#", ".", "[", "[href", "[href~x]", "[href='x]", "div > ", "a!"} {
+		_, err := parseSelector(invalid)
+		it.NotNil(err, invalid)
+	}
+}
+
+func Test_cssSelector(t *testing.T) {
+	it := assert.New(t)
+
+	doc, err := html.Parse(strings.NewReader(`<div class="card wide"><p lang="en-US">Hello, <em>world</em>!</p><p title="a b">Bye</p></div>`))
+	if !it.Nil(err) {
+		return
+	}
+
+	tests := []struct {
+		selector string
+		count    int
+	}{
+		{"p", 2},
+		{"*", 7}, // html, head, body, div, p, em, p
+		{"div p", 2},
+		{"body > p", 0},
+		{"div > p > em", 1},
+		{".card", 1},
+		{".card.narrow", 0},
+		{"[lang|=en]", 1},
+		{"[title~=b]", 1},
+		{"[title*=' ']", 1},
+		{"[title^='']", 0},
+		{"[class=card]", 0},
+		{"em, p", 3},
+	}
+	for _, test := range tests {
+		selector, err := parseSelector(test.selector)
+		if it.Nil(err, test.selector) {
+			it.Len(selector.selectAll(doc), test.count, test.selector)
+		}
+	}
+
+	selector, _ := parseSelector("p")
+	it.Equal("Hello, world!", htmlText(selector.selectAll(doc)[0]))
+}
@@ -0,0 +1,140 @@
+package httptesting
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ReceivedRequest defines an inbound request recorded by server created by NewServer or NewServerWithTLS.
+type ReceivedRequest struct {
+	Method     string
+	URL        *url.URL
+	Proto      string
+	Host       string
+	RemoteAddr string
+	Header     http.Header
+	Body       []byte
+	TLS        *tls.ConnectionState
+	ReceivedAt time.Time
+}
+
+// serve wraps handler of server created by NewServer or NewServerWithTLS for recording inbound requests
+// when WithRecordRequests option is given.
+func (c *Client) serve(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil {
+			c.receivedMux.Lock()
+			c.peerCerts = r.TLS.PeerCertificates
+			if c.connPeerCerts == nil {
+				c.connPeerCerts = map[string][]*x509.Certificate{}
+			}
+			c.connPeerCerts[r.RemoteAddr] = r.TLS.PeerCertificates
+			c.receivedMux.Unlock()
+		}
+
+		if !c.recordRequests {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		received := &ReceivedRequest{
+			Method:     r.Method,
+			URL:        cloneURL(r.URL),
+			Proto:      r.Proto,
+			Host:       r.Host,
+			RemoteAddr: r.RemoteAddr,
+			Header:     r.Header.Clone(),
+			TLS:        r.TLS,
+			ReceivedAt: time.Now(),
+		}
+
+		c.receivedMux.Lock()
+		c.received = append(c.received, received)
+		if c.recordLimit > 0 && len(c.received) > c.recordLimit {
+			c.received = append([]*ReceivedRequest(nil), c.received[len(c.received)-c.recordLimit:]...)
+		}
+		c.receivedMux.Unlock()
+
+		// NOTE: handler reads the live body, which is copied for recording
+		var body bytes.Buffer
+		if r.Body != nil {
+			r.Body = &teeBody{
+				Reader:     io.TeeReader(r.Body, &body),
+				ReadCloser: r.Body,
+			}
+		}
+
+		defer func() {
+			// record the rest of body unread by handler
+			if r.Body != nil {
+				io.Copy(io.Discard, r.Body)
+			}
+
+			c.receivedMux.Lock()
+			received.Body = body.Bytes()
+			c.receivedMux.Unlock()
+		}()
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+func (c *Client) serverTLSConfig() *tls.Config {
+	return &tls.Config{
+		ClientAuth: c.clientAuth,
+		ClientCAs:  c.clientCAs,
+	}
+}
+
+// ReceivedRequests returns copies of inbound requests recorded by server created by NewServer or NewServerWithTLS
+// in order of arrival, which are safe for inspecting on the test goroutine. Requests are recorded only
+// when WithRecordRequests option is given.
+func (c *Client) ReceivedRequests() []*ReceivedRequest {
+	c.receivedMux.Lock()
+	defer c.receivedMux.Unlock()
+
+	received := make([]*ReceivedRequest, 0, len(c.received))
+	for _, request := range c.received {
+		clone := *request
+
+		received = append(received, &clone)
+	}
+
+	return received
+}
+
+// ResetReceivedRequests discards all inbound requests recorded.
+func (c *Client) ResetReceivedRequests() {
+	c.receivedMux.Lock()
+	defer c.receivedMux.Unlock()
+
+	c.received = nil
+}
+
+type teeBody struct {
+	io.Reader
+	io.ReadCloser
+}
+
+func (body *teeBody) Read(p []byte) (int, error) {
+	return body.Reader.Read(p)
+}
+
+func cloneURL(u *url.URL) *url.URL {
+	if u == nil {
+		return nil
+	}
+
+	clone := *u
+	if u.User != nil {
+		user := *u.User
+		clone.User = &user
+	}
+
+	return &clone
+}
@@ -8,90 +8,77 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"net/http/cookiejar"
 	"net/http/httptest"
 	"net/url"
 
-	"github.com/dolab/httptesting/internal"
+	"github.com/dolab/httptesting/certs"
 )
 
-// NewServer returns an initialized *Client along with mocked server for testing
+// NewServer returns an initialized *Client along with mocked server for testing.
+// It starts a TLS server when WithTLS option is given.
 // NOTE: You MUST call client.Close() for cleanup after testing.
-func NewServer(handler http.Handler, isTLS bool) *Client {
-	var (
-		ts    *httptest.Server
-		certs *x509.CertPool
-	)
-	if isTLS {
-		cert, err := tls.X509KeyPair(internal.LocalhostCert, internal.LocalhostKey)
+func NewServer(handler http.Handler, opts ...Option) *Client {
+	client := newClient(false, opts)
+
+	ts := httptest.NewUnstartedServer(client.serve(handler))
+	if client.isTLS {
+		ca, err := certs.NewCA()
 		if err != nil {
 			panic(fmt.Sprintf("httptesting: NewTLSServer: %v", err))
 		}
 
-		ts = httptest.NewTLSServer(handler)
-
-		x509cert, err := x509.ParseCertificate(cert.Certificate[0])
+		cert, err := ca.Issue(certs.WithHosts("127.0.0.1", "::1", "localhost", "example.com"))
 		if err != nil {
 			panic(fmt.Sprintf("httptesting: NewTLSServer: %v", err))
 		}
 
-		certs = x509.NewCertPool()
-		certs.AddCert(x509cert)
-	} else {
-		ts = httptest.NewServer(handler)
-	}
+		ts.TLS = client.serverTLSConfig()
+		ts.TLS.Certificates = []tls.Certificate{cert}
+		ts.StartTLS()
 
-	jar, err := cookiejar.New(nil)
-	if err != nil {
-		panic(err.Error())
+		if client.certs == nil {
+			client.certs = ca.Pool()
+		}
+	} else {
+		ts.Start()
 	}
 
-	urlobj, err := url.Parse(ts.URL)
-	if err != nil {
-		panic(err.Error())
-	}
+	client.withServer(ts)
 
-	return &Client{
-		server: ts,
-		host:   urlobj.Host,
-		certs:  certs,
-		jar:    jar,
-		isTLS:  isTLS,
-	}
+	return client
 }
 
 // NewServerWithTLS returns an initialized *Client along with mocked server for testing
 // NOTE: You MUST call client.Close() for cleanup after testing.
-func NewServerWithTLS(handler http.Handler, cert tls.Certificate) *Client {
+func NewServerWithTLS(handler http.Handler, cert tls.Certificate, opts ...Option) *Client {
 	x509cert, err := x509.ParseCertificate(cert.Certificate[0])
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	ts := httptest.NewUnstartedServer(handler)
-	ts.TLS = &tls.Config{
-		Certificates: []tls.Certificate{cert},
-	}
-	ts.StartTLS()
+	client := newClient(true, opts)
 
-	certs := x509.NewCertPool()
-	certs.AddCert(x509cert)
+	ts := httptest.NewUnstartedServer(client.serve(handler))
+	ts.TLS = client.serverTLSConfig()
+	ts.TLS.Certificates = []tls.Certificate{cert}
+	ts.StartTLS()
 
-	jar, err := cookiejar.New(nil)
-	if err != nil {
-		panic(err.Error())
+	if client.certs == nil {
+		client.certs = x509.NewCertPool()
+		client.certs.AddCert(x509cert)
 	}
 
+	client.withServer(ts)
+
+	return client
+}
+
+func (c *Client) withServer(ts *httptest.Server) {
 	urlobj, err := url.Parse(ts.URL)
 	if err != nil {
 		panic(err.Error())
 	}
 
-	return &Client{
-		server: ts,
-		host:   urlobj.Host,
-		certs:  certs,
-		jar:    jar,
-		isTLS:  true,
-	}
+	c.server = ts
+	c.host = urlobj.Host
 }
@@ -5,77 +5,67 @@ package httptesting
 import (
 	"crypto/tls"
 	"crypto/x509"
+	"fmt"
 	"net/http"
-	"net/http/cookiejar"
 	"net/http/httptest"
 	"net/url"
 )
 
-// NewServer returns an initialized *Client along with mocked server for testing
+// NewServer returns an initialized *Client along with mocked server for testing.
+// It starts a TLS server when WithTLS option is given.
 // NOTE: You MUST call client.Close() for cleanup after testing.
-func NewServer(handler http.Handler, isTLS bool) *Client {
-	var (
-		ts    *httptest.Server
-		certs *x509.CertPool
-	)
-	if isTLS {
-		ts = httptest.NewTLSServer(handler)
+func NewServer(handler http.Handler, opts ...Option) *Client {
+	client := newClient(false, opts)
 
-		if transport, ok := ts.Client().Transport.(*http.Transport); ok {
-			certs = transport.TLSClientConfig.RootCAs
-		}
+	ts := httptest.NewUnstartedServer(client.serve(handler))
+	if client.isTLS {
+		ts.TLS = client.serverTLSConfig()
+		ts.StartTLS()
 	} else {
-		ts = httptest.NewServer(handler)
+		ts.Start()
 	}
 
-	jar, err := cookiejar.New(nil)
-	if err != nil {
-		panic(err.Error())
-	}
+	client.withServer(ts)
 
-	urlobj, err := url.Parse(ts.URL)
-	if err != nil {
-		panic(err.Error())
-	}
-
-	return &Client{
-		server: ts,
-		host:   urlobj.Host,
-		certs:  certs,
-		jar:    jar,
-		isTLS:  isTLS,
-	}
+	return client
 }
 
 // NewServerWithTLS returns an initialized *Client along with mocked server for testing
 // NOTE: You MUST call client.Close() for cleanup after testing.
-func NewServerWithTLS(handler http.Handler, cert tls.Certificate) *Client {
-	ts := httptest.NewUnstartedServer(handler)
-	ts.TLS = &tls.Config{
-		Certificates: []tls.Certificate{cert},
+func NewServerWithTLS(handler http.Handler, cert tls.Certificate, opts ...Option) *Client {
+	client := newClient(true, opts)
+	if client.certs == nil {
+		x509cert, err := x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			panic(fmt.Sprintf("httptesting: NewServerWithTLS: %v", err))
+		}
+
+		client.certs = x509.NewCertPool()
+		client.certs.AddCert(x509cert)
 	}
+
+	ts := httptest.NewUnstartedServer(client.serve(handler))
+	ts.TLS = client.serverTLSConfig()
+	ts.TLS.Certificates = []tls.Certificate{cert}
 	ts.StartTLS()
 
-	jar, err := cookiejar.New(nil)
-	if err != nil {
-		panic(err.Error())
-	}
+	client.withServer(ts)
 
+	return client
+}
+
+func (c *Client) withServer(ts *httptest.Server) {
 	urlobj, err := url.Parse(ts.URL)
 	if err != nil {
 		panic(err.Error())
 	}
 
-	var certs *x509.CertPool
-	if transport, ok := ts.Client().Transport.(*http.Transport); ok {
-		certs = transport.TLSClientConfig.RootCAs
-	}
+	c.server = ts
+	c.host = urlobj.Host
 
-	return &Client{
-		server: ts,
-		host:   urlobj.Host,
-		certs:  certs,
-		jar:    jar,
-		isTLS:  true,
+	if c.isTLS && c.certs == nil {
+		if transport, ok := ts.Client().Transport.(*http.Transport); ok {
+			c.certs = transport.TLSClientConfig.RootCAs
+		}
 	}
 }
@@ -1,12 +1,17 @@
 package httptesting
 
 import (
+	"bufio"
 	"crypto/tls"
 	"crypto/x509"
+	"errors"
+	"io"
 	"net/http"
+	"strconv"
 	"testing"
+	"time"
 
-	"github.com/dolab/httptesting/internal"
+	"github.com/dolab/httptesting/certs"
 	"github.com/golib/assert"
 )
 
@@ -22,7 +27,7 @@ func Test_NewServer(t *testing.T) {
 		w.Write([]byte("TLS"))
 	})
 
-	ts := NewServer(server, true)
+	ts := NewServer(server, WithTLS())
 	defer ts.Close()
 
 	it.NotNil(ts.server)
@@ -35,17 +40,26 @@ func Test_NewServer(t *testing.T) {
 	request.AssertOK()
 	request.AssertContains("TLS")
 
-	// it should work with custom TLS client
-	cert, err := tls.X509KeyPair(internal.LocalhostCert, internal.LocalhostKey)
+	// it should work with custom TLS client trusting certificate of the server
+	client := NewWithTLS(ts.Url(""), ts.server.Certificate())
+
+	request = client.New(t)
+	request.Get("/server/tls", nil)
+	request.AssertOK()
+	request.AssertContains("TLS")
+
+	// it should reject the server with unrelated CA
+	ca, err := certs.NewCA()
 	if it.Nil(err) {
-		x509cert, err := x509.ParseCertificate(cert.Certificate[0])
-		if it.Nil(err) {
-			client := NewWithTLS(ts.Url(""), x509cert)
-
-			request = client.New(t)
-			request.Get("/server/tls", nil)
-			request.AssertOK()
-			request.AssertContains("TLS")
+		client = NewWithTLS(ts.Url(""), ca.Certificate)
+
+		request = client.New(t).NonFatal()
+		request.Get("/server/tls", nil)
+		if it.NotNil(request.Err()) {
+			var e *Error
+			if it.True(errors.As(request.Err(), &e)) {
+				it.True(e.Certificate())
+			}
 		}
 	}
 }
@@ -62,7 +76,7 @@ func Test_NewServerWithTLS(t *testing.T) {
 		w.Write([]byte("TLS"))
 	})
 
-	cert, err := tls.X509KeyPair(internal.LocalhostCert, internal.LocalhostKey)
+	cert, err := newServerCertificate()
 	if it.Nil(err) {
 		ts := NewServerWithTLS(server, cert)
 		defer ts.Close()
@@ -78,14 +92,285 @@ func Test_NewServerWithTLS(t *testing.T) {
 		request.AssertContains("TLS")
 
 		// it should work with custom TLS client
-		x509cert, err := x509.ParseCertificate(cert.Certificate[0])
-		if it.Nil(err) {
-			client := NewWithTLS(ts.Url(""), x509cert)
-
-			request = client.New(t)
-			request.Get("/server/tls", nil)
-			request.AssertOK()
-			request.AssertContains("TLS")
-		}
+		client := NewWithTLS(ts.Url(""), cert.Leaf)
+
+		request = client.New(t)
+		request.Get("/server/tls", nil)
+		request.AssertOK()
+		request.AssertContains("TLS")
+	}
+}
+
+func Test_NewServerWithVerifiedTLS(t *testing.T) {
+	it := assert.New(t)
+
+	method := "GET"
+	uri := "/server/tls"
+	server := newMockServer(method, uri, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("TLS"))
+	})
+
+	cert, err := newServerCertificate()
+	if !it.Nil(err) {
+		return
+	}
+
+	// it should verify with supplied certificate
+	ts := NewServerWithTLS(server, cert)
+	defer ts.Close()
+
+	request := ts.New(t)
+	request.Get(uri)
+	request.AssertOK()
+	request.AssertContains("TLS")
+
+	// it should verify with custom server name
+	client := New(ts.Url(""), WithRootCAs(ts.certs), WithServerName("example.com"))
+
+	request = client.New(t)
+	request.Get(uri)
+	request.AssertOK()
+
+	// it should fail with mismatched server name
+	client = New(ts.Url(""), WithRootCAs(ts.certs), WithServerName("www.example.com"))
+
+	_, err = client.NewClient().Get(client.Url(uri))
+	if it.NotNil(err) {
+		ok, certErr := certificateError(err)
+		it.True(ok)
+		it.Contains(certErr.Error(), "www.example.com")
+	}
+
+	// it should fail with unknown authority
+	client = New(ts.Url(""), WithRootCAs(x509.NewCertPool()))
+
+	_, err = client.NewClient().Get(client.Url(uri))
+	if it.NotNil(err) {
+		ok, _ := certificateError(err)
+		it.True(ok)
+	}
+
+	// it should skip verification with insecure option
+	client = New(ts.Url(""), WithRootCAs(x509.NewCertPool()), WithServerName("www.example.com"), WithInsecureSkipVerify())
+
+	request = client.New(t)
+	request.Get(uri)
+	request.AssertOK()
+	it.Contains(request.Curl(), "--insecure")
+
+	// it should verify with filter transport
+	_, err = (&http.Client{Transport: NewFilterTransport(nil, x509.NewCertPool())}).Get(ts.Url(uri))
+	if it.NotNil(err) {
+		ok, _ := certificateError(err)
+		it.True(ok)
+	}
+
+	resp, err := (&http.Client{Transport: NewFilterTransport(nil, ts.certs)}).Get(ts.Url(uri))
+	if it.Nil(err) {
+		resp.Body.Close()
+		it.Equal(http.StatusOK, resp.StatusCode)
+	}
+}
+
+func Test_NewServerWithClientAuth(t *testing.T) {
+	it := assert.New(t)
+
+	method := "GET"
+	uri := "/server/mtls"
+	server := newMockServer(method, uri, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	})
+
+	ca, err := certs.NewCA()
+	if !it.Nil(err) {
+		return
+	}
+
+	clientCert, err := ca.Issue(certs.WithCommonName("httptesting"), certs.WithExtKeyUsage(x509.ExtKeyUsageClientAuth))
+	if !it.Nil(err) {
+		return
+	}
+
+	ts := NewServer(server,
+		WithTLS(),
+		WithClientAuth(tls.RequireAndVerifyClientCert, ca.Pool()),
+		WithClientCert(clientCert),
+	)
+	defer ts.Close()
+
+	// it should present client certificate
+	request := ts.New(t)
+	request.Get(uri)
+	request.AssertOK()
+	request.AssertContains("httptesting")
+	request.AssertPeerCertificate("httptesting")
+
+	// it should fail without client certificate
+	client := New(ts.Url(""), WithRootCAs(ts.certs))
+
+	_, err = client.NewClient().Get(client.Url(uri))
+	it.NotNil(err)
+}
+
+func Test_NewServerWithPeerCertificates(t *testing.T) {
+	it := assert.New(t)
+
+	server := newMockServer("GET", "/server/mtls", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	ca, err := certs.NewCA()
+	if !it.Nil(err) {
+		return
+	}
+
+	clientCert, err := ca.Issue(certs.WithCommonName("httptesting"), certs.WithExtKeyUsage(x509.ExtKeyUsageClientAuth))
+	if !it.Nil(err) {
+		return
+	}
+
+	ts := NewServer(server,
+		WithTLS(),
+		WithClientAuth(tls.VerifyClientCertIfGiven, ca.Pool()),
+		WithClientCert(clientCert),
+	)
+	defer ts.Close()
+
+	request := ts.New(t)
+	request.Get("/server/mtls")
+	request.AssertOK()
+
+	// it should keep certificate of the request after another request without certificate
+	resp, err := (&http.Client{Transport: NewFilterTransport(nil, ts.certs)}).Get(ts.Url("/server/mtls"))
+	if it.Nil(err) {
+		resp.Body.Close()
+	}
+
+	it.Empty(ts.PeerCertificates())
+	request.AssertPeerCertificate("httptesting")
+	if it.NotEmpty(request.PeerCertificates()) {
+		it.Equal("httptesting", request.PeerCertificates()[0].Subject.CommonName)
+	}
+}
+
+func Test_NewServerWithReceivedRequests(t *testing.T) {
+	it := assert.New(t)
+
+	server := newMockServer("POST", "/received", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	})
+
+	ts := NewServer(server, WithTLS(), WithBasePath("/api"), WithRecordRequests(0))
+	defer ts.Close()
+
+	started := time.Now()
+
+	request := ts.New(t)
+	request.WithHeader("X-Mock-Client", "httptesting")
+	request.Post("/received?name=httptesting", "text/plain", []byte("hello, server"))
+	request.AssertOK()
+	request.AssertContains("hello, server")
+	request.Get("/received")
+	request.AssertOK()
+
+	received := ts.ReceivedRequests()
+	if it.Len(received, 2) {
+		it.Equal("POST", received[0].Method)
+		it.Equal("/api/received", received[0].URL.Path)
+		it.Equal("name=httptesting", received[0].URL.RawQuery)
+		it.Equal("httptesting", received[0].Header.Get("X-Mock-Client"))
+		it.Equal([]byte("hello, server"), received[0].Body)
+		it.NotNil(received[0].TLS)
+		it.False(received[0].ReceivedAt.Before(started))
+		it.Empty(received[1].Body)
+	}
+
+	request.AssertReceivedCount(2)
+	request.AssertReceived("POST", "/received")
+	request.AssertReceived("post", "/received?name=httptesting")
+	request.AssertReceived("GET", "/received")
+
+	// it should report failures
+	harness := &mockTesting{}
+
+	request = ts.New(harness)
+	it.False(request.AssertReceived("DELETE", "/received"))
+	it.False(request.AssertReceived("POST", "/received?name=unknown"))
+	it.False(request.AssertReceivedCount(3))
+	if it.Len(harness.errors, 3) {
+		it.Contains(harness.errors[0], "Expected server received request of DELETE /api/received, but got [POST /api/received?name=httptesting GET /api/received]")
+		it.Contains(harness.errors[1], "Expected server received request of POST /api/received?name=unknown")
+		it.Contains(harness.errors[2], "Expected server received 3 request(s), but got 2")
+	}
+
+	ts.ResetReceivedRequests()
+	it.Empty(ts.ReceivedRequests())
+}
+
+func Test_NewServerWithRecordRequestsLimit(t *testing.T) {
+	it := assert.New(t)
+
+	server := newMockServer("POST", "/stream", func(w http.ResponseWriter, r *http.Request) {
+		// read only the first line of body
+		line, _ := bufio.NewReader(r.Body).ReadString('\n')
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(line))
+	})
+
+	ts := NewServer(server, WithRecordRequests(2))
+	defer ts.Close()
+
+	request := ts.New(t)
+	for i := 1; i <= 3; i++ {
+		request.Post("/stream", "text/plain", []byte(strconv.Itoa(i)+"\nrest of body"))
+		request.AssertOK()
+		request.AssertContains(strconv.Itoa(i) + "\n")
+	}
+
+	// it should keep the latest requests with whole body
+	received := ts.ReceivedRequests()
+	if it.Len(received, 2) {
+		it.Equal([]byte("2\nrest of body"), received[0].Body)
+		it.Equal([]byte("3\nrest of body"), received[1].Body)
 	}
 }
+
+func Test_NewServerWithoutRecordRequests(t *testing.T) {
+	it := assert.New(t)
+
+	server := newMockServer("GET", "/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	ts := NewServer(server)
+	defer ts.Close()
+
+	request := ts.New(t)
+	request.Get("/")
+	request.AssertOK()
+	it.Empty(ts.ReceivedRequests())
+
+	// it should report recording is disabled
+	harness := &mockTesting{}
+
+	request = ts.New(harness)
+	it.False(request.AssertReceivedCount(1))
+	if it.Len(harness.errors, 1) {
+		it.Contains(harness.errors[0], "WithRecordRequests")
+	}
+}
+
+func newServerCertificate() (tls.Certificate, error) {
+	ca, err := certs.NewCA()
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return ca.Issue(certs.WithHosts("127.0.0.1", "::1", "example.com"))
+}
@@ -0,0 +1,187 @@
+package httptesting
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/golib/assert"
+)
+
+// Timings defines durations of phases of a request captured by net/http/httptrace. A phase is zero
+// if it does not happen, e.g. DNS, Connect and TLSHandshake of a reused connection.
+type Timings struct {
+	DNS             time.Duration // DNS lookup
+	Connect         time.Duration // TCP connection establishment
+	TLSHandshake    time.Duration // TLS handshake
+	TimeToFirstByte time.Duration // from start of the request to the first byte of response
+	Total           time.Duration // from start of the request to response body read
+	Reused          bool          // whether the connection was reused from a previous request
+}
+
+// Timings returns timings of the latest request.
+func (r *Request) Timings() Timings {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if r.trace == nil {
+		return Timings{}
+	}
+
+	return r.trace.timings()
+}
+
+// AssertLatencyUnder asserts that the latest request completed in less than d, including reading response body.
+func (r *Request) AssertLatencyUnder(d time.Duration) bool {
+	if !r.assertResponse() {
+		return false
+	}
+
+	timings := r.Timings()
+
+	return assert.True(r.reporter(), timings.Total < d,
+		"Expected request completed under %v, but got %v (dns=%v connect=%v tls=%v ttfb=%v)",
+		d,
+		timings.Total,
+		timings.DNS,
+		timings.Connect,
+		timings.TLSHandshake,
+		timings.TimeToFirstByte,
+	)
+}
+
+// AssertConnectionReused asserts that the latest request was sent over a connection reused from previous requests.
+// It always fails for client created by NewHandler, which dispatches requests without connections.
+func (r *Request) AssertConnectionReused() bool {
+	if !r.assertResponse() {
+		return false
+	}
+
+	if r.Client.inMemory {
+		return assert.Fail(r.reporter(), "Connection reused (*unsupported)",
+			"Expected request sent over reused connection, but got request dispatched in memory by client of NewHandler",
+		)
+	}
+
+	timings := r.Timings()
+
+	return assert.True(r.reporter(), timings.Reused,
+		"Expected request sent over reused connection, but got a new connection (connect=%v tls=%v)",
+		timings.Connect,
+		timings.TLSHandshake,
+	)
+}
+
+// requestTrace records moments of a request with net/http/httptrace.
+type requestTrace struct {
+	mux          sync.Mutex
+	start        time.Time
+	getConn      time.Time
+	gotConn      time.Time
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+	done         time.Time
+	reused       bool
+	localAddr    string // local address of the latest connection, which is remote address seen by server
+}
+
+func newRequestTrace() *requestTrace {
+	return &requestTrace{
+		start: time.Now(),
+	}
+}
+
+// clientTrace returns hooks of httptrace recording moments into the trace. Only the first moment is kept
+// for starting events, and the last one for finishing events, since dialing may be attempted more than once.
+func (trace *requestTrace) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GetConn: func(hostPort string) {
+			trace.first(&trace.getConn)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			trace.mux.Lock()
+			trace.gotConn = time.Now()
+			trace.reused = info.Reused
+			if info.Conn != nil {
+				trace.localAddr = info.Conn.LocalAddr().String()
+			}
+			trace.mux.Unlock()
+		},
+		DNSStart: func(info httptrace.DNSStartInfo) {
+			trace.first(&trace.dnsStart)
+		},
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			trace.last(&trace.dnsDone)
+		},
+		ConnectStart: func(network, addr string) {
+			trace.first(&trace.connectStart)
+		},
+		ConnectDone: func(network, addr string, err error) {
+			trace.last(&trace.connectDone)
+		},
+		TLSHandshakeStart: func() {
+			trace.first(&trace.tlsStart)
+		},
+		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
+			trace.last(&trace.tlsDone)
+		},
+		WroteRequest: func(info httptrace.WroteRequestInfo) {
+			trace.last(&trace.wroteRequest)
+		},
+		GotFirstResponseByte: func() {
+			trace.first(&trace.firstByte)
+		},
+	}
+}
+
+func (trace *requestTrace) timings() Timings {
+	trace.mux.Lock()
+	defer trace.mux.Unlock()
+
+	var timings Timings
+	timings.DNS, _ = between(trace.dnsStart, trace.dnsDone)
+	timings.Connect, _ = between(trace.connectStart, trace.connectDone)
+	timings.TLSHandshake, _ = between(trace.tlsStart, trace.tlsDone)
+	timings.TimeToFirstByte, _ = between(trace.start, trace.firstByte)
+	timings.Total, _ = between(trace.start, trace.done)
+	timings.Reused = trace.reused
+
+	return timings
+}
+
+// finish records the moment of response body read.
+func (trace *requestTrace) finish() {
+	trace.last(&trace.done)
+}
+
+func (trace *requestTrace) first(moment *time.Time) {
+	trace.mux.Lock()
+	defer trace.mux.Unlock()
+
+	if moment.IsZero() {
+		*moment = time.Now()
+	}
+}
+
+func (trace *requestTrace) last(moment *time.Time) {
+	trace.mux.Lock()
+	defer trace.mux.Unlock()
+
+	*moment = time.Now()
+}
+
+// between returns duration from start to end, and false if either of them is absent.
+func between(start, end time.Time) (time.Duration, bool) {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return 0, false
+	}
+
+	return end.Sub(start), true
+}
@@ -0,0 +1,58 @@
+package httptesting
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golib/assert"
+)
+
+func TestRequest_Timings(t *testing.T) {
+	it := assert.New(t)
+
+	server := newMockServer("GET", "/timings", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("timings"))
+	})
+
+	client := NewServer(server, WithTLS())
+	defer client.Close()
+
+	harness := &mockTesting{}
+
+	request := client.New(harness)
+	it.Equal(Timings{}, request.Timings())
+
+	request.Get("/timings")
+	request.AssertOK()
+	request.AssertLatencyUnder(5 * time.Second)
+
+	timings := request.Timings()
+	it.False(timings.Reused)
+	it.True(timings.Connect > 0)
+	it.True(timings.TLSHandshake > 0)
+	it.True(timings.TimeToFirstByte >= 10*time.Millisecond)
+	it.True(timings.Total >= timings.TimeToFirstByte)
+	it.Empty(harness.errors)
+
+	// it should report failures
+	it.False(request.AssertConnectionReused())
+	it.False(request.AssertLatencyUnder(time.Millisecond))
+	if it.Len(harness.errors, 2) {
+		it.Contains(harness.errors[0], "Expected request sent over reused connection, but got a new connection")
+		it.Contains(harness.errors[1], "Expected request completed under 1ms, but got")
+	}
+
+	// it should reuse connection of keep-alive
+	request = client.New(t)
+	request.Get("/timings")
+	request.AssertOK()
+	request.AssertConnectionReused()
+
+	timings = request.Timings()
+	it.Equal(time.Duration(0), timings.Connect)
+	it.Equal(time.Duration(0), timings.TLSHandshake)
+}
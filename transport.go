@@ -1,9 +1,9 @@
 package httptesting
 
 import (
-	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"errors"
 	"net"
 	"net/http"
 	"time"
@@ -12,53 +12,183 @@ import (
 // RequestFilter is a callback for http request injection.
 type RequestFilter func(r *http.Request) error
 
-// FilterTransport defines a custom http.Transport with filters and certs.
-type FilterTransport struct {
-	filters []RequestFilter
-	certs   []*x509.CertPool
-}
+// ResponseFilter is a callback for http response inspection or rewriting.
+type ResponseFilter func(resp *http.Response) error
 
-func NewFilterTransport(filters []RequestFilter, certs ...*x509.CertPool) *FilterTransport {
-	return &FilterTransport{
-		filters: filters,
-		certs:   certs,
+// Middleware wraps a http.RoundTripper with another one, which is invoked once for each request
+// including redirects. Both Cassette.Transport and FaultInjector.Transport are middlewares.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RequestMiddleware returns a Middleware which invokes filter before sending the request.
+func RequestMiddleware(filter RequestFilter) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			if err := filter(r); err != nil {
+				return nil, err
+			}
+
+			return next.RoundTrip(r)
+		})
 	}
 }
 
-func (transport *FilterTransport) RoundTrip(r *http.Request) (*http.Response, error) {
-	tr := &http.Transport{
-		Proxy: http.ProxyFromEnvironment,
-		DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
-			// invoke filters
-			for _, filter := range transport.filters {
-				err := filter(r)
-				if err != nil {
-					return nil, err
-				}
+// ResponseMiddleware returns a Middleware which invokes filter after receiving the response.
+// The response is discarded if filter returns an error.
+func ResponseMiddleware(filter ResponseFilter) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			resp, err := next.RoundTrip(r)
+			if err != nil {
+				return resp, err
 			}
 
-			dialer := &net.Dialer{
-				Timeout:   10 * time.Second,
-				KeepAlive: 30 * time.Second,
-			}
+			if err := filter(resp); err != nil {
+				resp.Body.Close()
 
-			conn, err := dialer.Dial(network, address)
-			if err != nil {
 				return nil, err
 			}
 
-			return conn, nil
-		},
-		ResponseHeaderTimeout: 3 * time.Second,
+			return resp, nil
+		})
 	}
+}
+
+// roundTripperFunc is an adapter to allow the use of ordinary functions as http.RoundTripper.
+type roundTripperFunc func(r *http.Request) (*http.Response, error)
 
-	if len(transport.certs) > 0 {
-		tr.TLSClientConfig = &tls.Config{
-			RootCAs:            transport.certs[0],
-			InsecureSkipVerify: true,
+func (fn roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return fn(r)
+}
+
+// TransportConfig defines timeouts and connection pool settings of http.Transport created by Client.
+// Zero value of a field means no limit, see http.Transport for details.
+type TransportConfig struct {
+	DialTimeout           time.Duration
+	KeepAlive             time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
+	ExpectContinueTimeout time.Duration
+	IdleConnTimeout       time.Duration
+	MaxIdleConns          int
+	MaxIdleConnsPerHost   int
+	MaxConnsPerHost       int
+	DisableKeepAlives     bool
+}
+
+// DefaultTransportConfig is used by Client without WithTransportConfig option.
+var DefaultTransportConfig = TransportConfig{
+	DialTimeout:           10 * time.Second,
+	KeepAlive:             30 * time.Second,
+	TLSHandshakeTimeout:   10 * time.Second,
+	ResponseHeaderTimeout: 30 * time.Second,
+	ExpectContinueTimeout: time.Second,
+	IdleConnTimeout:       90 * time.Second,
+	MaxIdleConns:          100,
+	MaxIdleConnsPerHost:   10,
+}
+
+// NewTransport creates a *http.Transport with config and tls config given.
+func (config TransportConfig) NewTransport(tlsConfig *tls.Config) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   config.DialTimeout,
+		KeepAlive: config.KeepAlive,
+	}
+
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   config.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: config.ResponseHeaderTimeout,
+		ExpectContinueTimeout: config.ExpectContinueTimeout,
+		IdleConnTimeout:       config.IdleConnTimeout,
+		MaxIdleConns:          config.MaxIdleConns,
+		MaxIdleConnsPerHost:   config.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       config.MaxConnsPerHost,
+		DisableKeepAlives:     config.DisableKeepAlives,
+	}
+}
+
+// FilterTransport defines a custom http.RoundTripper which invokes filters and then middlewares
+// for each request before delegating to the underlying transport.
+type FilterTransport struct {
+	filters     []RequestFilter
+	middlewares []Middleware
+	transport   http.RoundTripper
+	chain       http.RoundTripper
+}
+
+// NewFilterTransport returns a *FilterTransport with a pooled transport verifying server certificates
+// against certs given, or the system pool if absent.
+func NewFilterTransport(filters []RequestFilter, certs ...*x509.CertPool) *FilterTransport {
+	var tlsConfig *tls.Config
+	if len(certs) > 0 {
+		tlsConfig = &tls.Config{
+			RootCAs: certs[0],
+		}
+	}
+
+	pooled := DefaultTransportConfig.NewTransport(tlsConfig)
+
+	return &FilterTransport{
+		filters:   filters,
+		transport: pooled,
+		chain:     pooled,
+	}
+}
+
+// Use appends middlewares to the transport, the first of which is the outermost.
+// The chain of middlewares is built here, so it should be called before sending requests.
+func (transport *FilterTransport) Use(middlewares ...Middleware) *FilterTransport {
+	transport.middlewares = append(transport.middlewares, middlewares...)
+	transport.chain = chainMiddlewares(transport.transport, transport.middlewares)
+
+	return transport
+}
+
+func (transport *FilterTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	// invoke filters
+	for _, filter := range transport.filters {
+		err := filter(r)
+		if err != nil {
+			return nil, err
 		}
-		tr.TLSHandshakeTimeout = 5 * time.Second
 	}
 
-	return tr.RoundTrip(r)
+	return transport.chain.RoundTrip(r)
+}
+
+// chainMiddlewares wraps transport with middlewares, the first of which is the outermost.
+func chainMiddlewares(transport http.RoundTripper, middlewares []Middleware) http.RoundTripper {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		transport = middlewares[i](transport)
+	}
+
+	return transport
+}
+
+// certificateError reports whether err is caused by server certificate verification, and returns the underlying error.
+func certificateError(err error) (bool, error) {
+	var (
+		verifyErr    *tls.CertificateVerificationError
+		hostnameErr  x509.HostnameError
+		authorityErr x509.UnknownAuthorityError
+		invalidErr   x509.CertificateInvalidError
+	)
+
+	switch {
+	case errors.As(err, &verifyErr):
+		return true, verifyErr.Err
+
+	case errors.As(err, &hostnameErr):
+		return true, hostnameErr
+
+	case errors.As(err, &authorityErr):
+		return true, authorityErr
+
+	case errors.As(err, &invalidErr):
+		return true, invalidErr
+	}
+
+	return false, nil
 }
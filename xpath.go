@@ -0,0 +1,147 @@
+package httptesting
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/antchfx/xpath"
+	"github.com/golib/assert"
+)
+
+// WithXMLNamespace binds prefix to namespace uri for XPath expressions of the request,
+// thus "ns:user" matches elements named user within the namespace regardless of prefix
+// used by the response body.
+func (r *Request) WithXMLNamespace(prefix, uri string) *Request {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if r.xmlNamespaces == nil {
+		r.xmlNamespaces = map[string]string{}
+	}
+	r.xmlNamespaces[prefix] = uri
+
+	return r
+}
+
+// AssertXPath asserts that the result of XPath expression evaluated against the response body
+// of XML is equal to expected in string form. The string value of the first node is used if the
+// expression selects nodes, e.g. "/user/name", "/user/@id", "count(//address)" or "normalize-space(/user/bio)".
+func (r *Request) AssertXPath(expr string, expected interface{}) bool {
+	result, ok := r.evaluateXPath(expr)
+	if !ok {
+		return false
+	}
+
+	var actual string
+	switch typo := result.(type) {
+	case *xpath.NodeIterator:
+		if !typo.MoveNext() {
+			return assert.Fail(r.reporter(), "Response body: XML node of "+expr+" (*required)",
+				"Expected response body contains XML node matching XPath %q, but got none",
+				expr,
+			)
+		}
+
+		actual = typo.Current().Value()
+
+	default:
+		actual = fmt.Sprint(typo)
+	}
+
+	return assert.EqualValues(r.reporter(), fmt.Sprint(expected), actual,
+		"Expected XPath %q of %v, but got %q",
+		expr,
+		expected,
+		actual,
+	)
+}
+
+// AssertXPathCount asserts that the response body of XML contains n nodes matching XPath expression.
+func (r *Request) AssertXPathCount(expr string, n int) bool {
+	nodes, ok := r.selectXPath(expr)
+	if !ok {
+		return false
+	}
+
+	return assert.EqualValues(r.reporter(), n, len(nodes),
+		"Expected response body contains %d XML node(s) matching XPath %q, but got %d",
+		n,
+		expr,
+		len(nodes),
+	)
+}
+
+// DecodeXPath decodes the first element matching XPath expression into v with encoding/xml.
+func (r *Request) DecodeXPath(expr string, v interface{}) bool {
+	nodes, ok := r.selectXPath(expr)
+	if !ok {
+		return false
+	}
+
+	if len(nodes) == 0 {
+		return assert.Fail(r.reporter(), "Response body: XML node of "+expr+" (*required)",
+			"Expected response body contains XML node matching XPath %q, but got none",
+			expr,
+		)
+	}
+
+	err := xml.Unmarshal([]byte(nodes[0].OutputXML(true)), v)
+	if err != nil {
+		return assert.Fail(r.reporter(), "Response body: XML node of "+expr+" (*invalid)",
+			"Expected XML node matching XPath %q decoded into %T, but got %v",
+			expr,
+			v,
+			err,
+		)
+	}
+
+	return true
+}
+
+// selectXPath returns nodes matching XPath expression within the response body of XML.
+func (r *Request) selectXPath(expr string) ([]*xmlquery.Node, bool) {
+	result, ok := r.evaluateXPath(expr)
+	if !ok {
+		return nil, false
+	}
+
+	iterator, ok := result.(*xpath.NodeIterator)
+	if !ok {
+		return nil, assert.Fail(r.reporter(), "XPath: "+expr+" (*invalid)",
+			"Expected XPath selects XML nodes, but got %T of %v",
+			result,
+			result,
+		)
+	}
+
+	var nodes []*xmlquery.Node
+	for iterator.MoveNext() {
+		nodes = append(nodes, iterator.Current().(*xmlquery.NodeNavigator).Current())
+	}
+
+	return nodes, true
+}
+
+// evaluateXPath evaluates XPath expression against the response body of XML, the result is one of
+// *xpath.NodeIterator, float64, string and bool.
+func (r *Request) evaluateXPath(expr string) (interface{}, bool) {
+	compiled, err := xpath.CompileWithNS(expr, r.xmlNamespaces)
+	if err != nil {
+		return nil, assert.Fail(r.reporter(), "XPath: "+expr+" (*invalid)",
+			"Expected valid XPath, but got %v",
+			err,
+		)
+	}
+
+	doc, err := xmlquery.Parse(bytes.NewReader(r.ResponseBody))
+	if err != nil {
+		return nil, assert.Fail(r.reporter(), "Response body: "+excerpt(r.ResponseBody, 0),
+			"Expected response body of XML, but got %v",
+			err,
+		)
+	}
+
+	return compiled.Evaluate(xmlquery.CreateXPathNavigator(doc)), true
+}
@@ -0,0 +1,83 @@
+package httptesting
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+func TestRequest_AssertXPath(t *testing.T) {
+	it := assert.New(t)
+
+	server := newMockServer("GET", "/xpath", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<users xmlns:a="urn:httptesting:address">
+	<user id="1">
+		<name>httptesting</name>
+		<age>3</age>
+		<a:address><a:name>china</a:name></a:address>
+		<a:address><a:name>USA</a:name></a:address>
+	</user>
+	<user id="2">
+		<name>golib</name>
+		<age>5</age>
+	</user>
+</users>`))
+	})
+
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	request := New(ts.URL).New(t)
+	request.GetXML("/xpath")
+	request.AssertOK()
+	request.AssertXPath("/users/user[1]/name", "httptesting")
+	request.AssertXPath("/users/user[2]/@id", 2)
+	request.AssertXPath("count(//user)", 2)
+	request.AssertXPath("sum(//age) > 7", true)
+	request.AssertXPathCount("//user[age > 3]", 1)
+	request.AssertXPathCount("//a:address", 2)
+
+	// it should work with namespace bound to other prefix
+	request.WithXMLNamespace("addr", "urn:httptesting:address")
+	request.AssertXPath("//addr:address[2]/addr:name", "USA")
+	request.AssertXPathCount("//addr:address", 2)
+
+	// it should decode matched node
+	var user struct {
+		XMLName xml.Name `xml:"user"`
+		ID      int      `xml:"id,attr"`
+		Name    string   `xml:"name"`
+		Age     int      `xml:"age"`
+	}
+	if request.DecodeXPath("//user[name='golib']", &user) {
+		it.Equal(2, user.ID)
+		it.Equal("golib", user.Name)
+		it.Equal(5, user.Age)
+	}
+
+	// it should report failures
+	harness := &mockTesting{}
+
+	request = New(ts.URL).New(harness)
+	request.GetXML("/xpath")
+	it.False(request.AssertXPath("/users/user[1]/name", "golib"))
+	it.False(request.AssertXPath("/users/user[3]/name", "golib"))
+	it.False(request.AssertXPathCount("//user", 3))
+	it.False(request.AssertXPathCount("count(//user)", 2))
+	it.False(request.AssertXPath("//user[", ""))
+	it.False(request.DecodeXPath("//user[name='dolab']", &user))
+	if it.Len(harness.errors, 6) {
+		it.Contains(harness.errors[0], `Expected XPath "/users/user[1]/name" of golib, but got "httptesting"`)
+		it.Contains(harness.errors[1], "but got none")
+		it.Contains(harness.errors[2], `contains 3 XML node(s) matching XPath "//user", but got 2`)
+		it.Contains(harness.errors[3], "Expected XPath selects XML nodes, but got float64 of 2")
+		it.Contains(harness.errors[4], "Expected valid XPath")
+		it.Contains(harness.errors[5], "but got none")
+	}
+}